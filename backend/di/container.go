@@ -10,14 +10,28 @@ import (
 )
 
 type Container struct {
-	DB         *sql.DB
-	JWTService *infrastructure.JWTService
-	Handlers   *handler.Handlers
+	DB               *sql.DB
+	JWTService       *infrastructure.JWTService
+	AuthUsecase      *usecase.AuthUsecase
+	ViewRecorder     *usecase.ViewRecorder
+	Handlers         *handler.Handlers
+	ClientIPResolver *handler.ClientIPResolver
 }
 
 type Config struct {
 	DB                  infrastructure.Config
 	JWT                 infrastructure.JWTConfig
+	Mail                infrastructure.MailConfig
+	LoginThrottle       usecase.LoginThrottleConfig
+	SecretBox           infrastructure.SecretBoxConfig
+	OwnerTag            infrastructure.OwnerTagConfig
+	Reply               usecase.ReplyConfig
+	Reaction            usecase.ReactionConfig
+	Tag                 usecase.TagConfig
+	View                usecase.ViewConfig
+	Event               usecase.EventConfig
+	OIDC                infrastructure.OIDCConfig
+	ClientIP            handler.ClientIPConfig
 	StripeAPIKey        string `envconfig:"STRIPE_API_KEY" required:"true"`
 	StripePriceID       string `envconfig:"STRIPE_PRICE_ID" required:"true"`
 	StripeWebhookSecret string `envconfig:"STRIPE_WEBHOOK_SECRET" required:"true"`
@@ -33,17 +47,89 @@ func NewContainer(config Config) (*Container, error) {
 	}
 
 	// JWT Service
-	jwtService := infrastructure.NewJWTService(config.JWT)
+	jwtService, err := infrastructure.NewJWTService(config.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listener for user events written by any instance
+	eventListener, err := infrastructure.NewEventListener(config.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encryption of TOTP secrets at rest
+	secretBox, err := infrastructure.NewSecretBox(config.SecretBox)
+	if err != nil {
+		return nil, err
+	}
+
+	// Owner tags of anonymous replies
+	ownerTagger, err := infrastructure.NewOwnerTagger(config.OwnerTag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mailer
+	mailer, err := infrastructure.NewMailer(config.Mail)
+	if err != nil {
+		return nil, err
+	}
+
+	// OIDC provider
+	if config.OIDC.RedirectURL == "" {
+		config.OIDC.RedirectURL = config.BaseURL + "/auth/oidc/callback"
+	}
+	oidcProvider := infrastructure.NewOIDCProvider(config.OIDC)
 
 	// Repositories
 	userRepo := repository.NewUserRepository(db)
 	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	revokedTokenRepo := repository.NewRevokedTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	accountUnlockRepo := repository.NewAccountUnlockRepository(db)
+	personalAccessTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
 	postRepo := repository.NewPostRepository(db)
+	postRevisionRepo := repository.NewPostRevisionRepository(db)
 	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+	postViewRepo := repository.NewPostViewRepository(db)
 
 	// Usecases
-	authUsecase := usecase.NewAuthUsecase(userRepo, passwordResetRepo, jwtService)
-	postUsecase := usecase.NewPostUsecase(postRepo, userRepo)
+	loginThrottler := usecase.NewLoginThrottler(loginThrottleRepo, config.LoginThrottle)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(twoFactorRepo, userRepo, loginThrottler, secretBox)
+	personalAccessTokenUsecase := usecase.NewPersonalAccessTokenUsecase(personalAccessTokenRepo, userRepo)
+	authUsecase := usecase.NewAuthUsecase(
+		userRepo,
+		passwordResetRepo,
+		emailVerificationRepo,
+		refreshTokenRepo,
+		sessionRepo,
+		revokedTokenRepo,
+		accountUnlockRepo,
+		twoFactorUsecase,
+		personalAccessTokenUsecase,
+		loginThrottler,
+		jwtService,
+		mailer,
+		config.BaseURL,
+	)
+	oidcUsecase := usecase.NewOIDCUsecase(identityRepo, userRepo, oidcProvider, authUsecase)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo)
+	eventUsecase := usecase.NewEventUsecase(eventRepo, eventListener, config.Event)
+	viewRecorder := usecase.NewViewRecorder(postViewRepo, config.View)
+	postUsecase := usecase.NewPostUsecase(postRepo, userRepo, postRevisionRepo, notificationUsecase, eventUsecase, viewRecorder, ownerTagger, config.Reply, config.Reaction, config.Tag)
+	bookmarkUsecase := usecase.NewBookmarkUsecase(bookmarkRepo, postUsecase)
+	followUsecase := usecase.NewFollowUsecase(followRepo, userRepo, postRepo)
+	postStatsUsecase := usecase.NewPostStatsUsecase(postViewRepo, postRepo)
 	subscriptionUsecase := usecase.NewSubscriptionUsecase(
 		userRepo,
 		subscriptionRepo,
@@ -60,18 +146,46 @@ func NewContainer(config Config) (*Container, error) {
 	adminHandler := handler.NewAdminHandler(authUsecase, postUsecase, userRepo)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionUsecase, config.StripeWebhookSecret)
 	userHandler := handler.NewUserHandler(userRepo)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUsecase)
+	jwksHandler := handler.NewJWKSHandler(jwtService)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenUsecase)
+	oidcHandler := handler.NewOIDCHandler(oidcUsecase)
+	sessionHandler := handler.NewSessionHandler(authUsecase)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkUsecase)
+	followHandler := handler.NewFollowHandler(followUsecase)
+	notificationHandler := handler.NewNotificationHandler(notificationUsecase)
+	eventHandler := handler.NewEventHandler(eventUsecase, config.Event)
+	postStatsHandler := handler.NewPostStatsHandler(postStatsUsecase)
+
+	clientIPResolver, err := handler.NewClientIPResolver(config.ClientIP)
+	if err != nil {
+		return nil, err
+	}
 
 	handlers := &handler.Handlers{
-		Auth:         authHandler,
-		Post:         postHandler,
-		Admin:        adminHandler,
-		Subscription: subscriptionHandler,
-		User:         userHandler,
+		Auth:                authHandler,
+		Post:                postHandler,
+		Admin:               adminHandler,
+		Subscription:        subscriptionHandler,
+		User:                userHandler,
+		TwoFactor:           twoFactorHandler,
+		JWKS:                jwksHandler,
+		PersonalAccessToken: personalAccessTokenHandler,
+		OIDC:                oidcHandler,
+		Session:             sessionHandler,
+		Bookmark:            bookmarkHandler,
+		Follow:              followHandler,
+		Notification:        notificationHandler,
+		Event:               eventHandler,
+		PostStats:           postStatsHandler,
 	}
 
 	return &Container{
-		DB:         db,
-		JWTService: jwtService,
-		Handlers:   handlers,
+		DB:               db,
+		JWTService:       jwtService,
+		AuthUsecase:      authUsecase,
+		ViewRecorder:     viewRecorder,
+		Handlers:         handlers,
+		ClientIPResolver: clientIPResolver,
 	}, nil
 }
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTypeReplyCreated       EventType = "reply_created"
+	EventTypePostApproved       EventType = "post_approved"
+	EventTypePostRejected       EventType = "post_rejected"
+	EventTypeGroupMemberAdded   EventType = "group_member_added"
+	EventTypeGroupMemberRemoved EventType = "group_member_removed"
+	EventTypeGroupMemberLeft    EventType = "group_member_left"
+	EventTypeGroupPostPublished EventType = "group_post_published"
+)
+
+// Event is something pushed to one user over the event stream. IDs only
+// increase, so a client can resume after the last event it received. Data
+// is the JSON encoding of an EventData.
+type Event struct {
+	ID        int64           `json:"id" db:"id"`
+	UserID    int             `json:"-" db:"user_id"`
+	Type      EventType       `json:"type" db:"type"`
+	Data      json.RawMessage `json:"data" db:"data"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// EventData identifies what an event is about. Events carry IDs only;
+// clients fetch the details through the API, which checks access.
+type EventData struct {
+	PostID        *int `json:"post_id,omitempty"`
+	ReplyID       *int `json:"reply_id,omitempty"`
+	ParentReplyID *int `json:"parent_reply_id,omitempty"`
+	GroupID       *int `json:"group_id,omitempty"`
+	UserID        *int `json:"user_id,omitempty"`
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+type NotificationType string
+
+const (
+	NotificationTypePostApproved NotificationType = "post_approved"
+	NotificationTypePostRejected NotificationType = "post_rejected"
+	NotificationTypeReply        NotificationType = "reply"
+	NotificationTypeReaction     NotificationType = "reaction"
+	NotificationTypeGroupAdded   NotificationType = "group_added"
+	NotificationTypeMention      NotificationType = "mention"
+)
+
+// NotificationTypes lists every type a user can turn on or off
+var NotificationTypes = []NotificationType{
+	NotificationTypePostApproved,
+	NotificationTypePostRejected,
+	NotificationTypeReply,
+	NotificationTypeReaction,
+	NotificationTypeGroupAdded,
+	NotificationTypeMention,
+}
+
+// IsValid reports whether t is one of NotificationTypes
+func (t NotificationType) IsValid() bool {
+	for _, notificationType := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells UserID that something happened to one of their posts,
+// replies or groups. Actor is nil when the action was taken by a moderator
+// or anonymously. PostTitle and GroupName are filled in when listing, and
+// PostTitle is left out once the user can no longer see the post.
+type Notification struct {
+	ID        int                `json:"id" db:"id"`
+	UserID    int                `json:"-" db:"user_id"`
+	Type      NotificationType   `json:"type" db:"type"`
+	ActorID   *int               `json:"-" db:"actor_id"`
+	Actor     *NotificationActor `json:"actor"`
+	PostID    *int               `json:"post_id" db:"post_id"`
+	PostTitle *string            `json:"post_title,omitempty"`
+	ReplyID   *int               `json:"reply_id" db:"reply_id"`
+	GroupID   *int               `json:"group_id" db:"group_id"`
+	GroupName *string            `json:"group_name,omitempty"`
+	Emoji     *string            `json:"emoji,omitempty" db:"emoji"`
+	ReadAt    *time.Time         `json:"read_at" db:"read_at"`
+	CreatedAt time.Time          `json:"created_at" db:"created_at"`
+}
+
+// NotificationActor is the user who caused a notification
+type NotificationActor struct {
+	ID          int    `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+// NotificationPage is one page of a user's notifications, newest first.
+// UnreadCount covers all of the user's notifications, not just this page.
+type NotificationPage struct {
+	Notifications []Notification
+	Total         *int
+	UnreadCount   int
+	NextCursor    *PostCursor
+}
+
+// NotificationPreference is whether a user receives one type of notification
+type NotificationPreference struct {
+	Type    NotificationType `json:"type"`
+	Enabled bool             `json:"enabled"`
+}
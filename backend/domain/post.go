@@ -1,36 +1,87 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
 type Post struct {
-	ID           int        `json:"id" db:"id"`
-	Title        string     `json:"title" db:"title"`
-	Content      string     `json:"content" db:"content"`
-	ThumbnailURL *string    `json:"thumbnail_url" db:"thumbnail_url"`
-	AuthorID     int        `json:"-" db:"author_id"`
-	Author       *User      `json:"author,omitempty"`
-	Status       PostStatus `json:"status" db:"status"`
-	IsDeleted    bool       `json:"is_deleted" db:"is_deleted"`
-	GroupID      *int       `json:"group_id" db:"group_id"`
-	Group        *Group     `json:"group,omitempty"`
-	Categories   []Category `json:"categories,omitempty"`
-	LikesCount   int        `json:"likes_count" db:"likes_count"`
-	IsLiked      bool       `json:"is_liked" db:"is_liked"`
-	Replies      []Reply    `json:"replies,omitempty"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	ID            int             `json:"id" db:"id"`
+	Title         string          `json:"title" db:"title"`
+	Content       string          `json:"content" db:"content"`
+	ContentFormat ContentFormat   `json:"content_format" db:"content_format"`
+	ContentHTML   string          `json:"content_html" db:"content_html"`
+	ThumbnailURL  *string         `json:"thumbnail_url" db:"thumbnail_url"`
+	AuthorID      int             `json:"-" db:"author_id"`
+	Author        *User           `json:"author,omitempty"`
+	Status        PostStatus      `json:"status" db:"status"`
+	IsDeleted     bool            `json:"is_deleted" db:"is_deleted"`
+	GroupID       *int            `json:"group_id" db:"group_id"`
+	Group         *Group          `json:"group,omitempty"`
+	Categories    []Category      `json:"categories,omitempty"`
+	Tags          []string        `json:"tags,omitempty"`
+	LikesCount    int             `json:"likes_count" db:"likes_count"`
+	IsLiked       bool            `json:"is_liked" db:"is_liked"`
+	Reactions     []ReactionCount `json:"reactions,omitempty"`
+	MyReactions   []string        `json:"my_reactions,omitempty"`
+	IsBookmarked  bool            `json:"is_bookmarked" db:"-"`
+	Mentions      []Mention       `json:"mentions,omitempty"`
+	RepliesCount  int             `json:"replies_count" db:"replies_count"`
+	PublishAt     *time.Time      `json:"publish_at" db:"publish_at"`
+	PublishedAt   *time.Time      `json:"published_at" db:"published_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
 }
 
+// IsPublished reports whether the post is approved and its publish time, if
+// scheduled, has been reached
+func (p *Post) IsPublished() bool {
+	return p.Status == PostStatusApproved && p.PublishedAt != nil
+}
+
+// Reply is a reply to a post or, when ParentReplyID is set, to another
+// reply. A deleted reply is kept as a tombstone with no content or author so
+// that the replies under it stay in place. Anonymous replies record no
+// AuthorID; their author is recognized by OwnerTag instead.
 type Reply struct {
-	ID          int       `json:"id" db:"id"`
-	Content     string    `json:"content" db:"content"`
-	PostID      int       `json:"post_id" db:"post_id"`
-	AuthorID    *int      `json:"-" db:"author_id"`
-	Author      *User     `json:"author" db:"-"`
-	IsAnonymous bool      `json:"is_anonymous" db:"is_anonymous"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID            int             `json:"id" db:"id"`
+	Content       string          `json:"content" db:"content"`
+	ContentFormat ContentFormat   `json:"content_format" db:"content_format"`
+	ContentHTML   string          `json:"content_html" db:"content_html"`
+	PostID        int             `json:"post_id" db:"post_id"`
+	ParentReplyID *int            `json:"parent_reply_id" db:"parent_reply_id"`
+	Depth         int             `json:"depth" db:"depth"`
+	AuthorID      *int            `json:"-" db:"author_id"`
+	Author        *User           `json:"author" db:"-"`
+	OwnerTag      *string         `json:"-" db:"owner_tag"`
+	IsAnonymous   bool            `json:"is_anonymous" db:"is_anonymous"`
+	IsDeleted     bool            `json:"is_deleted" db:"is_deleted"`
+	Reactions     []ReactionCount `json:"reactions,omitempty"`
+	MyReactions   []string        `json:"my_reactions,omitempty"`
+	Mentions      []Mention       `json:"mentions,omitempty"`
+	RepliesCount  int             `json:"replies_count" db:"replies_count"`
+	EditedAt      *time.Time      `json:"edited_at" db:"edited_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// ReactionLike is the reaction the like toggle adds and removes. It is what
+// likes_count and is_liked report.
+const ReactionLike = "like"
+
+// ReactionCount is how many users added one reaction
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// Mention is a user mentioned with @display_name in the content of a post
+// or reply. Start and End are offsets in characters (code points) of the
+// content, from the @ to just after the name.
+type Mention struct {
+	UserID      int    `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
 }
 
 type Category struct {
@@ -42,6 +93,14 @@ type Category struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// TrendingTag is a #hashtag with how many recently published posts, and
+// by how many authors, use it
+type TrendingTag struct {
+	Tag          string `json:"tag"`
+	PostsCount   int    `json:"posts_count"`
+	AuthorsCount int    `json:"authors_count"`
+}
+
 type Like struct {
 	ID        int       `json:"id" db:"id"`
 	PostID    int       `json:"post_id" db:"post_id"`
@@ -75,7 +134,148 @@ type GroupMember struct {
 type PostStatus string
 
 const (
+	PostStatusDraft    PostStatus = "draft"
 	PostStatusPending  PostStatus = "pending"
 	PostStatusApproved PostStatus = "approved"
 	PostStatusRejected PostStatus = "rejected"
 )
+
+// PostSearchParams narrows a full-text post search. Terms must all match;
+// From and Until bound the publication time, From inclusive and Until
+// exclusive.
+type PostSearchParams struct {
+	Terms      []string
+	CategoryID *int
+	AuthorID   *int
+	GroupID    *int
+	From       *time.Time
+	Until      *time.Time
+}
+
+// PostSearchResult is a post matched by a search, with the matched terms
+// wrapped in <mark> in the HTML-escaped title and snippet
+type PostSearchResult struct {
+	Post             *Post   `json:"post"`
+	Rank             float64 `json:"rank"`
+	TitleHighlighted string  `json:"title_highlighted"`
+	Snippet          string  `json:"snippet"`
+}
+
+// PostCursor is the position of a post in listings ordered by (timestamp,
+// id). CreatedAt is the timestamp the listing is ordered by, which is
+// published_at in listings of published posts.
+type PostCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// PostPageRequest selects one page of a post listing. With After set the page
+// starts right after that post; otherwise Page is used as an offset.
+type PostPageRequest struct {
+	Page      int
+	Limit     int
+	After     *PostCursor
+	WithTotal bool
+}
+
+// PostPage is one page of a post listing. Total is only counted when
+// requested, and NextCursor is nil on the last page.
+type PostPage struct {
+	Posts      []*Post
+	Total      *int
+	NextCursor *PostCursor
+}
+
+// ReplyPage is one page of the replies to a post or to a reply, oldest
+// first. The cursor has the same form as for posts.
+type ReplyPage struct {
+	Replies    []Reply
+	Total      *int
+	NextCursor *PostCursor
+}
+
+// PostRevision is a snapshot of a post as saved by one create, update or
+// restore. Revision numbers start at 1 for each post.
+type PostRevision struct {
+	ID            int           `json:"id" db:"id"`
+	PostID        int           `json:"post_id" db:"post_id"`
+	Revision      int           `json:"revision" db:"revision"`
+	Title         string        `json:"title" db:"title"`
+	Content       string        `json:"content" db:"content"`
+	ContentFormat ContentFormat `json:"content_format" db:"content_format"`
+	ThumbnailURL  *string       `json:"thumbnail_url" db:"thumbnail_url"`
+	CategoryIDs   []int         `json:"category_ids" db:"category_ids"`
+	EditorID      *int          `json:"editor_id" db:"editor_id"`
+	EditorName    *string       `json:"editor_name" db:"-"`
+	RestoredFrom  *int          `json:"restored_from" db:"restored_from"`
+	ApprovedAt    *time.Time    `json:"approved_at" db:"approved_at"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+}
+
+type DiffOp string
+
+const (
+	DiffOpEqual  DiffOp = "equal"
+	DiffOpInsert DiffOp = "insert"
+	DiffOpDelete DiffOp = "delete"
+)
+
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// PostRevisionDiff is a line diff of title and content between two revisions
+type PostRevisionDiff struct {
+	From    *PostRevision `json:"from"`
+	To      *PostRevision `json:"to"`
+	Title   []DiffLine    `json:"title"`
+	Content []DiffLine    `json:"content"`
+}
+
+type ContentFormat string
+
+const (
+	ContentFormatPlain    ContentFormat = "plain"
+	ContentFormatMarkdown ContentFormat = "markdown"
+)
+
+// ParseContentFormat accepts "plain" and "markdown", defaulting to plain
+func ParseContentFormat(value string) (ContentFormat, error) {
+	switch ContentFormat(value) {
+	case "", ContentFormatPlain:
+		return ContentFormatPlain, nil
+	case ContentFormatMarkdown:
+		return ContentFormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unsupported content format %q", value)
+}
+
+// Bookmark is a post a user saved for later, optionally in a named
+// collection
+type Bookmark struct {
+	ID             int       `json:"id" db:"id"`
+	UserID         int       `json:"-" db:"user_id"`
+	PostID         int       `json:"post_id" db:"post_id"`
+	CollectionID   *int      `json:"collection_id" db:"collection_id"`
+	CollectionName *string   `json:"collection_name" db:"-"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	Post           *Post     `json:"post,omitempty"`
+}
+
+// BookmarkCollection is a named group of one user's bookmarks.
+// BookmarksCount only counts bookmarks whose posts the user can still see.
+type BookmarkCollection struct {
+	ID             int       `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	BookmarksCount int       `json:"bookmarks_count" db:"-"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// BookmarkPage is one page of a user's bookmarks, newest first. The cursor
+// is the position of the last bookmark.
+type BookmarkPage struct {
+	Bookmarks  []*Bookmark
+	Total      *int
+	NextCursor *PostCursor
+}
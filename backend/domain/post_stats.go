@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// PostView is a user viewing a post on a day. Views are counted once per
+// user and day, so ViewedOn is a UTC date.
+type PostView struct {
+	PostID   int
+	UserID   int
+	ViewedOn time.Time
+}
+
+// PostStatsTotals are the all-time counts of one or more posts. Views counts
+// each user once per day and UniqueViewers once in all.
+type PostStatsTotals struct {
+	Views         int `json:"views"`
+	UniqueViewers int `json:"unique_viewers"`
+	Likes         int `json:"likes"`
+	Replies       int `json:"replies"`
+}
+
+// DailyPostStats are the views, likes and replies of one UTC day
+type DailyPostStats struct {
+	Date    string `json:"date"`
+	Views   int    `json:"views"`
+	Likes   int    `json:"likes"`
+	Replies int    `json:"replies"`
+}
+
+// PostStats are the statistics of one post, with a day by day series over
+// the requested period
+type PostStats struct {
+	PostID int    `json:"post_id"`
+	Title  string `json:"title"`
+	PostStatsTotals
+	Daily []DailyPostStats `json:"daily"`
+}
+
+// PostViewCount is how often a post was viewed over a period
+type PostViewCount struct {
+	PostID int    `json:"post_id"`
+	Title  string `json:"title"`
+	Views  int    `json:"views"`
+}
+
+// PostStatsSummary sums the statistics of all of an author's posts other
+// than drafts and deleted ones. TopPosts are those viewed most over the
+// period of Daily.
+type PostStatsSummary struct {
+	PostsCount int `json:"posts_count"`
+	PostStatsTotals
+	Daily    []DailyPostStats `json:"daily"`
+	TopPosts []PostViewCount  `json:"top_posts"`
+}
@@ -15,6 +15,7 @@ type User struct {
 	StripeCustomerID   *string                `json:"-" db:"stripe_customer_id"`
 	IsActive           bool                   `json:"is_active" db:"is_active"`
 	EmailVerified      bool                   `json:"-" db:"email_verified"`
+	TokenVersion       int                    `json:"-" db:"token_version"`
 	CreatedAt          time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time              `json:"updated_at" db:"updated_at"`
 }
@@ -43,3 +44,134 @@ type PasswordReset struct {
 	UsedAt    *time.Time `json:"used_at" db:"used_at"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 }
+
+type EmailVerification struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	FamilyID  string     `json:"family_id" db:"family_id"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type TwoFactorCredential struct {
+	UserID       int        `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt  *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type LoginThrottleScope string
+
+const (
+	LoginThrottleScopeAccount LoginThrottleScope = "account"
+	LoginThrottleScopeIP      LoginThrottleScope = "ip"
+)
+
+type LoginThrottle struct {
+	Scope          LoginThrottleScope `json:"scope" db:"scope"`
+	Key            string             `json:"key" db:"key"`
+	FailedAttempts int                `json:"failed_attempts" db:"failed_attempts"`
+	LastFailedAt   *time.Time         `json:"last_failed_at" db:"last_failed_at"`
+	LockedUntil    *time.Time         `json:"locked_until" db:"locked_until"`
+}
+
+type AccountUnlock struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type TokenScope string
+
+const (
+	TokenScopePostsRead    TokenScope = "posts:read"
+	TokenScopePostsWrite   TokenScope = "posts:write"
+	TokenScopeGroupsManage TokenScope = "groups:manage"
+)
+
+// TokenScopes lists every scope a personal access token can be granted
+var TokenScopes = []TokenScope{
+	TokenScopePostsRead,
+	TokenScopePostsWrite,
+	TokenScopeGroupsManage,
+}
+
+type PersonalAccessToken struct {
+	ID          int          `json:"id" db:"id"`
+	UserID      int          `json:"user_id" db:"user_id"`
+	Name        string       `json:"name" db:"name"`
+	TokenPrefix string       `json:"token_prefix" db:"token_prefix"`
+	TokenHash   string       `json:"-" db:"token_hash"`
+	Scopes      []TokenScope `json:"scopes" db:"scopes"`
+	LastUsedAt  *time.Time   `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt   *time.Time   `json:"expires_at" db:"expires_at"`
+	RevokedAt   *time.Time   `json:"-" db:"revoked_at"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+}
+
+// Identity links a user to an account at an external OpenID Connect provider
+type Identity struct {
+	ID          int        `json:"id" db:"id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       *string    `json:"email" db:"email"`
+	LastLoginAt *time.Time `json:"last_login_at" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type OIDCLoginState struct {
+	StateHash    string    `json:"-" db:"state_hash"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	Nonce        string    `json:"-" db:"nonce"`
+	IPAddress    string    `json:"-" db:"ip_address"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Session is one login of a user, shared by all tokens issued from it
+type Session struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	UserAgent  *string    `json:"user_agent" db:"user_agent"`
+	IPAddress  *string    `json:"ip_address" db:"ip_address"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"-" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// FollowUser is a user in a follower or following list, with only public
+// profile fields
+type FollowUser struct {
+	ID          int       `json:"id"`
+	DisplayName string    `json:"display_name"`
+	Bio         *string   `json:"bio"`
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+// FollowPage is one page of a follower or following list, most recent
+// follows first. The cursor is the follow time and the listed user's ID.
+type FollowPage struct {
+	Users      []FollowUser
+	Total      *int
+	NextCursor *PostCursor
+}
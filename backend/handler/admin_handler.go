@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"posting-app/domain"
@@ -39,24 +40,29 @@ func (h *AdminHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, token, err := h.authUsecase.AdminLogin(req.Email, req.Password)
+	user, tokens, err := h.authUsecase.AdminLogin(req.Email, req.Password, getClientInfo(r))
+	if errors.Is(err, usecase.ErrTooManyLoginAttempts) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if errors.Is(err, usecase.ErrTwoFactorEnrollmentRequired) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	// Remove sensitive data
-	user.PasswordHash = ""
-
-	writeJSON(w, http.StatusOK, LoginResponse{
-		User:        user,
-		AccessToken: token,
-	})
+	writeLoginResponse(w, user, tokens)
 }
 
 func (h *AdminHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
-	page := getQueryInt(r, "page", 1)
-	limit := getQueryInt(r, "limit", 20)
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var status *domain.PostStatus
 	statusParam := r.URL.Query().Get("status")
@@ -74,20 +80,13 @@ func (h *AdminHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	posts, total, err := h.postUsecase.GetPostsForAdmin(page, limit, status)
+	page, err := h.postUsecase.GetPostsForAdmin(pageReq, status)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response := PaginatedResponse{
-		Data:  posts,
-		Total: total,
-		Page:  page,
-		Limit: limit,
-	}
-
-	writeJSON(w, http.StatusOK, response)
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
 }
 
 func (h *AdminHandler) ApprovePost(w http.ResponseWriter, r *http.Request) {
@@ -143,7 +142,7 @@ func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 	response := PaginatedResponse{
 		Data:  users,
-		Total: total,
+		Total: &total,
 		Page:  page,
 		Limit: limit,
 	}
@@ -168,3 +167,21 @@ func (h *AdminHandler) BanUser(w http.ResponseWriter, r *http.Request) {
 		Message: "User banned successfully",
 	})
 }
+
+func (h *AdminHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	err = h.authUsecase.UnlockUser(userID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "User unlocked successfully",
+	})
+}
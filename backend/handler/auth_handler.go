@@ -2,8 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"posting-app/domain"
 	"posting-app/usecase"
 )
 
@@ -32,19 +34,48 @@ type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required"`
 	NewPassword string `json:"new_password" validate:"required,min=8"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type VerifyTwoFactorRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
 
 type LoginResponse struct {
-	User        interface{} `json:"user"`
-	AccessToken string      `json:"access_token"`
+	User         interface{} `json:"user"`
+	AccessToken  string      `json:"access_token"`
+	RefreshToken string      `json:"refresh_token"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of tokens when the
+// account has two-factor authentication enabled
+type TwoFactorChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -59,7 +90,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.authUsecase.Register(req.Email, req.Password, req.DisplayName)
+	user, err := h.authUsecase.Register(req.Email, req.Password, req.DisplayName, getLanguage(r))
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -69,7 +100,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	user.PasswordHash = ""
 
 	writeJSON(w, http.StatusCreated, Response{
-		Message: "User registered successfully",
+		Message: "User registered successfully. Please check your email to verify your account",
 		Data:    user,
 	})
 }
@@ -86,7 +117,57 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, token, err := h.authUsecase.Login(req.Email, req.Password)
+	user, tokens, err := h.authUsecase.Login(req.Email, req.Password, getClientInfo(r))
+	if errors.Is(err, usecase.ErrTooManyLoginAttempts) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	writeLoginResponse(w, user, tokens)
+}
+
+func (h *AuthHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req VerifyTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, tokens, err := h.authUsecase.VerifyTwoFactor(req.MFAToken, req.Code, getClientInfo(r))
+	if errors.Is(err, usecase.ErrTooManyLoginAttempts) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	writeLoginResponse(w, user, tokens)
+}
+
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, tokens, err := h.authUsecase.RefreshToken(req.RefreshToken)
 	if err != nil {
 		writeError(w, http.StatusUnauthorized, err.Error())
 		return
@@ -96,17 +177,93 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	user.PasswordHash = ""
 
 	writeJSON(w, http.StatusOK, LoginResponse{
-		User:        user,
-		AccessToken: token,
+		User:         user,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	})
 }
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	err := h.authUsecase.Logout(getBearerToken(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
 	writeJSON(w, http.StatusOK, Response{
 		Message: "Logged out successfully",
 	})
 }
 
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.authUsecase.VerifyEmail(req.Token)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "Email verified successfully",
+	})
+}
+
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	var req UnlockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.authUsecase.UnlockAccount(req.Token)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "Account unlocked successfully",
+	})
+}
+
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.authUsecase.ResendVerification(req.Email, getLanguage(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to process verification request")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "Verification email sent if account exists",
+	})
+}
+
 func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	var req ForgotPasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -119,7 +276,7 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.authUsecase.ForgotPassword(req.Email)
+	err := h.authUsecase.ForgotPassword(req.Email, getLanguage(r))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to process password reset request")
 		return
@@ -181,3 +338,22 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		Message: "Password changed successfully",
 	})
 }
+
+func writeLoginResponse(w http.ResponseWriter, user *domain.User, tokens *usecase.AuthTokens) {
+	if tokens.MFAToken != "" {
+		writeJSON(w, http.StatusOK, TwoFactorChallengeResponse{
+			MFARequired: true,
+			MFAToken:    tokens.MFAToken,
+		})
+		return
+	}
+
+	// Remove sensitive data
+	user.PasswordHash = ""
+
+	writeJSON(w, http.StatusOK, LoginResponse{
+		User:         user,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
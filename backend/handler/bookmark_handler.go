@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"posting-app/usecase"
+)
+
+type BookmarkHandler struct {
+	bookmarkUsecase *usecase.BookmarkUsecase
+}
+
+func NewBookmarkHandler(bookmarkUsecase *usecase.BookmarkUsecase) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkUsecase: bookmarkUsecase,
+	}
+}
+
+type BookmarkRequest struct {
+	Collection string `json:"collection" validate:"max=100"`
+}
+
+func (h *BookmarkHandler) Bookmark(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	// The body is optional; without it the post is saved outside any collection
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bookmark, err := h.bookmarkUsecase.Bookmark(user.ID, postID, req.Collection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bookmark)
+}
+
+func (h *BookmarkHandler) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	err = h.bookmarkUsecase.RemoveBookmark(user.ID, postID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BookmarkHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collectionID, err := getQueryOptionalInt(r, "collection_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid collection_id")
+		return
+	}
+
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.bookmarkUsecase.GetBookmarks(user.ID, collectionID, pageReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PaginatedResponse{
+		Data:       page.Bookmarks,
+		Total:      page.Total,
+		Page:       pageReq.Page,
+		Limit:      pageReq.Limit,
+		NextCursor: encodeCursor(page.NextCursor),
+	})
+}
+
+func (h *BookmarkHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collections, err := h.bookmarkUsecase.GetCollections(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, collections)
+}
+
+func (h *BookmarkHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	collectionID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid collection ID")
+		return
+	}
+
+	err = h.bookmarkUsecase.DeleteCollection(user.ID, collectionID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
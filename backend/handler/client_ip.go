@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type ClientIPConfig struct {
+	// TrustedProxies lists the addresses, in CIDR notation, of the reverse
+	// proxies in front of the server. X-Forwarded-For is only read from
+	// requests they forward.
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+}
+
+// ClientIPResolver determines the address of the client. Headers such as
+// X-Forwarded-For are set by the client unless a proxy overwrites them, so
+// they are only believed as far as they were appended by trusted proxies.
+// Anything else would let a client pick the address that login throttling
+// counts against.
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+func NewClientIPResolver(config ClientIPConfig) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, cidr := range config.TrustedProxies {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", cidr, err)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, network)
+	}
+	return resolver, nil
+}
+
+// Middleware replaces RemoteAddr with the resolved client address, so that
+// getClientIP and the request logger see it
+func (c *ClientIPResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = c.Resolve(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Resolve returns the client address of r. It starts from the peer address
+// and, while that is a trusted proxy, steps to the hop the proxy appended to
+// X-Forwarded-For, i.e. the right-most one not yet consumed.
+func (c *ClientIPResolver) Resolve(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0 && c.isTrusted(ip); i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip
+}
+
+func (c *ClientIPResolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range c.trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
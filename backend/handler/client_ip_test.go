@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPResolverResolve(t *testing.T) {
+	resolver, err := NewClientIPResolver(ClientIPConfig{TrustedProxies: []string{"10.0.0.0/8", " 192.168.1.1/32 "}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		trueClientIP string
+		expectedIP   string
+	}{
+		{"direct request", "203.0.113.7:51234", nil, "", "203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:51234", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:443", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"client-supplied hops are ignored", "10.1.2.3:443", []string{"192.0.2.99, 198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:443", []string{"192.0.2.99, 198.51.100.1, 192.168.1.1"}, "", "198.51.100.1"},
+		{"multiple headers", "10.1.2.3:443", []string{"192.0.2.99", "198.51.100.1"}, "", "198.51.100.1"},
+		{"malformed hop", "10.1.2.3:443", []string{"198.51.100.1, not-an-ip"}, "", "10.1.2.3"},
+		{"trusted proxy without header", "10.1.2.3:443", nil, "", "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.trueClientIP != "" {
+				r.Header.Set("True-Client-IP", tt.trueClientIP)
+			}
+
+			if ip := resolver.Resolve(r); ip != tt.expectedIP {
+				t.Errorf("expected %s, got %s", tt.expectedIP, ip)
+			}
+		})
+	}
+}
+
+func TestNewClientIPResolverRejectsInvalidCIDR(t *testing.T) {
+	if _, err := NewClientIPResolver(ClientIPConfig{TrustedProxies: []string{"10.0.0.1"}}); err == nil {
+		t.Error("expected an error for an address without a prefix length")
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"posting-app/usecase"
+)
+
+// eventRetryInterval is how long clients wait before reconnecting, in
+// milliseconds
+const eventRetryInterval = 3000
+
+type EventHandler struct {
+	eventUsecase *usecase.EventUsecase
+	config       usecase.EventConfig
+}
+
+func NewEventHandler(eventUsecase *usecase.EventUsecase, config usecase.EventConfig) *EventHandler {
+	return &EventHandler{
+		eventUsecase: eventUsecase,
+		config:       config,
+	}
+}
+
+// Stream sends the caller's events as Server-Sent Events. A client that
+// sends Last-Event-ID gets the events it missed since then first; other
+// clients only get events from now on. The stream ends after the configured
+// duration and clients reconnect with the last ID they received.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	var lastEventID int64
+	resume := false
+	if lastEventIDStr := r.Header.Get("Last-Event-ID"); lastEventIDStr != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 64)
+		if err != nil || lastEventID < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+		resume = true
+	}
+
+	// Subscribe before reading so that no event falls between the two
+	wake, unsubscribe := h.eventUsecase.Subscribe(user.ID)
+	defer unsubscribe()
+
+	if !resume {
+		var err error
+		lastEventID, err = h.eventUsecase.GetLatestEventID(user.ID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetryInterval)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.config.Heartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(h.config.StreamDuration)
+	defer deadline.Stop()
+
+	for {
+		events, err := h.eventUsecase.GetEventsAfter(user.ID, lastEventID)
+		if err != nil {
+			slog.Error("Failed to read events for stream", "user_id", user.ID, "error", err)
+			return
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.Error("Failed to encode event", "event_id", event.ID, "error", err)
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			lastEventID = event.ID
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case <-wake:
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"posting-app/domain"
+	"posting-app/usecase"
+)
+
+type FollowHandler struct {
+	followUsecase *usecase.FollowUsecase
+}
+
+func NewFollowHandler(followUsecase *usecase.FollowUsecase) *FollowHandler {
+	return &FollowHandler{
+		followUsecase: followUsecase,
+	}
+}
+
+func (h *FollowHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
+	h.handleFollow(w, r, "Invalid user ID", h.followUsecase.FollowUser)
+}
+
+func (h *FollowHandler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	h.handleFollow(w, r, "Invalid user ID", h.followUsecase.UnfollowUser)
+}
+
+func (h *FollowHandler) FollowCategory(w http.ResponseWriter, r *http.Request) {
+	h.handleFollow(w, r, "Invalid category ID", h.followUsecase.FollowCategory)
+}
+
+func (h *FollowHandler) UnfollowCategory(w http.ResponseWriter, r *http.Request) {
+	h.handleFollow(w, r, "Invalid category ID", h.followUsecase.UnfollowCategory)
+}
+
+// handleFollow applies a follow or unfollow of the {id} path parameter by
+// the current user
+func (h *FollowHandler) handleFollow(w http.ResponseWriter, r *http.Request, invalidIDMessage string, apply func(userID, targetID int) error) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	targetID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, invalidIDMessage)
+		return
+	}
+
+	err = apply(user.ID, targetID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FollowHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	h.handleFollowList(w, r, h.followUsecase.GetFollowers)
+}
+
+func (h *FollowHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	h.handleFollowList(w, r, h.followUsecase.GetFollowing)
+}
+
+func (h *FollowHandler) handleFollowList(w http.ResponseWriter, r *http.Request, list func(userID int, pageReq domain.PostPageRequest) (*domain.FollowPage, error)) {
+	userID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := list(userID, pageReq)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PaginatedResponse{
+		Data:       page.Users,
+		Total:      page.Total,
+		Page:       pageReq.Page,
+		Limit:      pageReq.Limit,
+		NextCursor: encodeCursor(page.NextCursor),
+	})
+}
+
+func (h *FollowHandler) GetFollowedCategories(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	categories, err := h.followUsecase.GetFollowedCategories(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, categories)
+}
@@ -2,11 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"posting-app/usecase"
 )
 
 type Response struct {
@@ -19,11 +23,15 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// PaginatedResponse is one page of a listing. Total is omitted when it was
+// not counted; NextCursor is set by cursor-paginated listings while more
+// results remain.
 type PaginatedResponse struct {
-	Data  interface{} `json:"data"`
-	Total int         `json:"total"`
-	Page  int         `json:"page"`
-	Limit int         `json:"limit"`
+	Data       interface{} `json:"data"`
+	Total      *int        `json:"total,omitempty"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 var validate = validator.New()
@@ -56,3 +64,65 @@ func getQueryInt(r *http.Request, param string, defaultValue int) int {
 
 	return value
 }
+
+// getQueryOptionalInt returns nil when the parameter is absent
+func getQueryOptionalInt(r *http.Request, param string) (*int, error) {
+	paramStr := r.URL.Query().Get(param)
+	if paramStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(paramStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// getQueryTime parses an RFC 3339 timestamp or a YYYY-MM-DD date and reports
+// whether only a date was given. It returns nil when the parameter is absent.
+func getQueryTime(r *http.Request, param string) (*time.Time, bool, error) {
+	paramStr := r.URL.Query().Get(param)
+	if paramStr == "" {
+		return nil, false, nil
+	}
+
+	if value, err := time.Parse(time.DateOnly, paramStr); err == nil {
+		return &value, true, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, paramStr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &value, false, nil
+}
+
+// getLanguage returns the primary language tag of the Accept-Language header,
+// e.g. "ja" for "ja-JP,ja;q=0.9,en;q=0.8"
+func getLanguage(r *http.Request) string {
+	lang := strings.SplitN(r.Header.Get("Accept-Language"), ",", 2)[0]
+	lang = strings.SplitN(lang, ";", 2)[0]
+	lang = strings.SplitN(strings.TrimSpace(lang), "-", 2)[0]
+	return strings.ToLower(lang)
+}
+
+// getClientIP returns the client address without the port. RemoteAddr has
+// already been resolved by ClientIPResolver.
+func getClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func getClientInfo(r *http.Request) usecase.ClientInfo {
+	return usecase.ClientInfo{
+		IPAddress: getClientIP(r),
+		UserAgent: r.UserAgent(),
+		Language:  getLanguage(r),
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"posting-app/infrastructure"
+)
+
+type JWKSHandler struct {
+	jwtService *infrastructure.JWTService
+}
+
+func NewJWKSHandler(jwtService *infrastructure.JWTService) *JWKSHandler {
+	return &JWKSHandler{
+		jwtService: jwtService,
+	}
+}
+
+// GetJWKS serves the public keys other services use to verify our tokens
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, h.jwtService.JWKS())
+}
@@ -6,16 +6,17 @@ import (
 	"strings"
 
 	"posting-app/domain"
-	"posting-app/infrastructure"
+	"posting-app/usecase"
 )
 
 type contextKey string
 
 const (
-	UserContextKey contextKey = "user"
+	UserContextKey      contextKey = "user"
+	PrincipalContextKey contextKey = "principal"
 )
 
-func AuthMiddleware(jwtService *infrastructure.JWTService) func(http.Handler) http.Handler {
+func AuthMiddleware(authUsecase *usecase.AuthUsecase) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -30,19 +31,14 @@ func AuthMiddleware(jwtService *infrastructure.JWTService) func(http.Handler) ht
 				return
 			}
 
-			claims, err := jwtService.ValidateToken(tokenString)
+			principal, err := authUsecase.Authenticate(tokenString)
 			if err != nil {
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			user := &domain.User{
-				ID:    claims.UserID,
-				Email: claims.Email,
-				Role:  domain.UserRole(claims.Role),
-			}
-
-			ctx := context.WithValue(r.Context(), UserContextKey, user)
+			ctx := context.WithValue(r.Context(), UserContextKey, principal.User)
+			ctx = context.WithValue(ctx, PrincipalContextKey, principal)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -61,6 +57,53 @@ func AdminMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Admin tokens must come from a login that passed the second factor
+		principal := GetPrincipalFromContext(r.Context())
+		if principal == nil || !principal.MFA {
+			http.Error(w, "Two-factor authentication required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireScope lets personal access tokens through only if they were granted
+// scope. Session tokens always pass.
+func RequireScope(scope domain.TokenScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := GetPrincipalFromContext(r.Context())
+			if principal == nil {
+				http.Error(w, "User not found in context", http.StatusUnauthorized)
+				return
+			}
+
+			if !principal.HasScope(scope) {
+				http.Error(w, "Token lacks required scope: "+string(scope), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SessionOnly rejects personal access tokens for routes no scope covers, such
+// as account settings and token management.
+func SessionOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := GetPrincipalFromContext(r.Context())
+		if principal == nil {
+			http.Error(w, "User not found in context", http.StatusUnauthorized)
+			return
+		}
+
+		if principal.IsPersonalAccessToken() {
+			http.Error(w, "Personal access tokens cannot be used here", http.StatusForbidden)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -69,3 +112,12 @@ func GetUserFromContext(ctx context.Context) *domain.User {
 	user, _ := ctx.Value(UserContextKey).(*domain.User)
 	return user
 }
+
+func GetPrincipalFromContext(ctx context.Context) *usecase.Principal {
+	principal, _ := ctx.Value(PrincipalContextKey).(*usecase.Principal)
+	return principal
+}
+
+func getBearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"posting-app/domain"
+	"posting-app/usecase"
+)
+
+type NotificationHandler struct {
+	notificationUsecase *usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(notificationUsecase *usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{
+		notificationUsecase: notificationUsecase,
+	}
+}
+
+// NotificationPageResponse is a page of notifications with the user's total
+// number of unread ones
+type NotificationPageResponse struct {
+	PaginatedResponse
+	UnreadCount int `json:"unread_count"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []domain.NotificationPreference `json:"preferences" validate:"required,min=1"`
+}
+
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	unreadOnly := false
+	if unreadStr := r.URL.Query().Get("unread"); unreadStr != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(unreadStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid unread flag")
+			return
+		}
+	}
+
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.notificationUsecase.GetNotifications(user.ID, unreadOnly, pageReq)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NotificationPageResponse{
+		PaginatedResponse: PaginatedResponse{
+			Data:       page.Notifications,
+			Total:      page.Total,
+			Page:       pageReq.Page,
+			Limit:      pageReq.Limit,
+			NextCursor: encodeCursor(page.NextCursor),
+		},
+		UnreadCount: page.UnreadCount,
+	})
+}
+
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	count, err := h.notificationUsecase.GetUnreadCount(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{"unread_count": count})
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	notificationID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	err = h.notificationUsecase.MarkRead(user.ID, notificationID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	count, err := h.notificationUsecase.MarkAllRead(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int64{"marked_read": count})
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	preferences, err := h.notificationUsecase.GetPreferences(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preferences, err := h.notificationUsecase.UpdatePreferences(user.ID, req.Preferences)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, preferences)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"posting-app/usecase"
+)
+
+type OIDCHandler struct {
+	oidcUsecase *usecase.OIDCUsecase
+}
+
+func NewOIDCHandler(oidcUsecase *usecase.OIDCUsecase) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUsecase: oidcUsecase,
+	}
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// oidcStateCookie binds a started login to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	login, err := h.oidcUsecase.BeginLogin(getClientInfo(r))
+	if errors.Is(err, usecase.ErrOIDCNotConfigured) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, usecase.ErrTooManyPendingLogins) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "Failed to start external login")
+		return
+	}
+
+	cookie := newOIDCStateCookie(r)
+	cookie.Value = login.State
+	cookie.Expires = login.ExpiresAt
+	http.SetCookie(w, cookie)
+
+	writeJSON(w, http.StatusOK, OIDCAuthorizeResponse{
+		AuthorizationURL: login.AuthorizationURL,
+	})
+}
+
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var browserState string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	cookie := newOIDCStateCookie(r)
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+
+	user, tokens, err := h.oidcUsecase.CompleteLogin(req.Code, req.State, browserState, getClientInfo(r))
+	if errors.Is(err, usecase.ErrOIDCNotConfigured) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	writeLoginResponse(w, user, tokens)
+}
+
+// newOIDCStateCookie returns the state cookie without a value. The frontend
+// calls the API from another site, and browsers only store and send such a
+// cookie on those requests with SameSite=None, which in turn requires Secure.
+// Plain HTTP is only used for local development on one site, where Lax works.
+func newOIDCStateCookie(r *http.Request) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/auth/oidc",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if isSecureRequest(r) {
+		cookie.Secure = true
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+// isSecureRequest reports whether the client reached the server over HTTPS,
+// directly or through a TLS-terminating proxy
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"posting-app/infrastructure"
+	"posting-app/repository"
+	"posting-app/usecase"
+)
+
+// emptyConnector opens connections to a fake database that counts the
+// queries it receives and answers every one with no rows
+type emptyConnector struct {
+	queries int
+}
+
+func (c *emptyConnector) Connect(context.Context) (driver.Conn, error) {
+	return &emptyConn{connector: c}, nil
+}
+
+func (c *emptyConnector) Driver() driver.Driver {
+	return nil
+}
+
+type emptyConn struct {
+	connector *emptyConnector
+}
+
+func (c *emptyConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *emptyConn) Close() error {
+	return nil
+}
+
+func (c *emptyConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *emptyConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.connector.queries++
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string {
+	return make([]string, 5)
+}
+
+func (emptyRows) Close() error {
+	return nil
+}
+
+func (emptyRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+func TestOIDCCallbackUsesStateCookie(t *testing.T) {
+	tests := []struct {
+		name            string
+		cookie          string
+		expectedQueries int
+		expectedMessage string
+	}{
+		// The login state is unknown to the fake database, so a request that
+		// gets past the browser check fails when the state is looked up
+		{"state cookie of this login", "login-state", 1, "invalid or expired login state"},
+		{"state cookie of another login", "another-state", 0, "login state does not belong to this browser"},
+		{"no state cookie", "", 0, "login state does not belong to this browser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &emptyConnector{}
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			provider := infrastructure.NewOIDCProvider(infrastructure.OIDCConfig{
+				Issuer:   "https://issuer.example",
+				ClientID: "posting-app",
+			})
+			h := NewOIDCHandler(usecase.NewOIDCUsecase(repository.NewIdentityRepository(db), nil, provider, nil))
+
+			r := httptest.NewRequest("POST", "/auth/oidc/callback", strings.NewReader(`{"code":"code","state":"login-state"}`))
+			r.Header.Set("X-Forwarded-Proto", "https")
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			h.Callback(w, r)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+			var response ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, response.Message)
+			}
+			if connector.queries != tt.expectedQueries {
+				t.Errorf("expected %d queries, got %d", tt.expectedQueries, connector.queries)
+			}
+
+			cookies := w.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Name != oidcStateCookie || cookies[0].MaxAge >= 0 {
+				t.Fatalf("expected the state cookie to be cleared, got %v", cookies)
+			}
+			if !cookies[0].Secure || cookies[0].SameSite != http.SameSiteNoneMode {
+				t.Errorf("expected a Secure SameSite=None cookie over HTTPS, got %v", cookies[0])
+			}
+		})
+	}
+}
+
+func TestNewOIDCStateCookieSameSite(t *testing.T) {
+	tests := []struct {
+		name             string
+		forwardedProto   string
+		expectedSecure   bool
+		expectedSameSite http.SameSite
+	}{
+		// The frontend calls the API cross-site, which needs SameSite=None
+		{"https", "https", true, http.SameSiteNoneMode},
+		// Local development over HTTP is same-site, and None requires Secure
+		{"http", "", false, http.SameSiteLaxMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/auth/oidc/authorize", nil)
+			if tt.forwardedProto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.forwardedProto)
+			}
+
+			cookie := newOIDCStateCookie(r)
+			if cookie.Secure != tt.expectedSecure || cookie.SameSite != tt.expectedSameSite {
+				t.Errorf("expected Secure=%v SameSite=%v, got Secure=%v SameSite=%v",
+					tt.expectedSecure, tt.expectedSameSite, cookie.Secure, cookie.SameSite)
+			}
+			if !cookie.HttpOnly || cookie.Path != "/auth/oidc" {
+				t.Errorf("expected an HttpOnly cookie for /auth/oidc, got %v", cookie)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"posting-app/domain"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// postCursor is the JSON form of domain.PostCursor. Clients only ever see it
+// base64url encoded and must treat it as opaque.
+type postCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+}
+
+func encodeCursor(cursor *domain.PostCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(postCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*domain.PostCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor postCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID <= 0 || cursor.CreatedAt.IsZero() {
+		return nil, errors.New("incomplete cursor")
+	}
+
+	return &domain.PostCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}, nil
+}
+
+// getPostPageRequest reads page, limit, cursor and include_total. A cursor
+// takes precedence over page. The total is counted by default for page
+// numbers and only on request for cursors, since counting defeats the point
+// of keyset pagination on large tables.
+func getPostPageRequest(r *http.Request) (domain.PostPageRequest, error) {
+	pageReq := domain.PostPageRequest{
+		Page:  getQueryInt(r, "page", 1),
+		Limit: getQueryInt(r, "limit", defaultPageLimit),
+	}
+	if pageReq.Page < 1 {
+		pageReq.Page = 1
+	}
+	if pageReq.Limit < 1 {
+		pageReq.Limit = defaultPageLimit
+	}
+	if pageReq.Limit > maxPageLimit {
+		pageReq.Limit = maxPageLimit
+	}
+
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return pageReq, errors.New("invalid cursor")
+		}
+		pageReq.After = after
+		pageReq.Page = 1
+	}
+
+	pageReq.WithTotal = pageReq.After == nil
+	if includeTotal := r.URL.Query().Get("include_total"); includeTotal != "" {
+		withTotal, err := strconv.ParseBool(includeTotal)
+		if err != nil {
+			return pageReq, errors.New("invalid include_total")
+		}
+		pageReq.WithTotal = withTotal
+	}
+
+	return pageReq, nil
+}
+
+func newPostPageResponse(pageReq domain.PostPageRequest, page *domain.PostPage) PaginatedResponse {
+	return PaginatedResponse{
+		Data:       page.Posts,
+		Total:      page.Total,
+		Page:       pageReq.Page,
+		Limit:      pageReq.Limit,
+		NextCursor: encodeCursor(page.NextCursor),
+	}
+}
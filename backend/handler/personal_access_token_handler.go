@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"posting-app/domain"
+	"posting-app/usecase"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenUsecase *usecase.PersonalAccessTokenUsecase
+}
+
+func NewPersonalAccessTokenHandler(tokenUsecase *usecase.PersonalAccessTokenUsecase) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenUsecase: tokenUsecase,
+	}
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name          string              `json:"name" validate:"required,max=100"`
+	Scopes        []domain.TokenScope `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays *int                `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// CreatePersonalAccessTokenResponse is the only response that contains the
+// plain token
+type CreatePersonalAccessTokenResponse struct {
+	Token               string                      `json:"token"`
+	PersonalAccessToken *domain.PersonalAccessToken `json:"personal_access_token"`
+}
+
+func (h *PersonalAccessTokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tokens, err := h.tokenUsecase.List(user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (h *PersonalAccessTokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req CreatePersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, plainToken, err := h.tokenUsecase.Create(user.ID, req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, CreatePersonalAccessTokenResponse{
+		Token:               plainToken,
+		PersonalAccessToken: token,
+	})
+}
+
+func (h *PersonalAccessTokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	tokenID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	err = h.tokenUsecase.Revoke(user.ID, tokenID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "Token revoked successfully",
+	})
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"posting-app/domain"
 	"posting-app/usecase"
 )
 
@@ -46,8 +49,15 @@ type AddGroupMemberRequest struct {
 }
 
 type CreateReplyRequest struct {
-	Content     string `json:"content" validate:"required,max=2000"`
-	IsAnonymous bool   `json:"is_anonymous"`
+	Content       string `json:"content" validate:"required,max=2000"`
+	ContentFormat string `json:"content_format" validate:"omitempty,oneof=plain markdown"`
+	IsAnonymous   bool   `json:"is_anonymous"`
+	ParentReplyID *int   `json:"parent_reply_id"`
+}
+
+type UpdateReplyRequest struct {
+	Content       string `json:"content" validate:"required,max=2000"`
+	ContentFormat string `json:"content_format" validate:"omitempty,oneof=plain markdown"`
 }
 
 func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +84,12 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	contentFormat, err := domain.ParseContentFormat(r.FormValue("content_format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "content_format must be plain or markdown")
+		return
+	}
+
 	// Parse category IDs
 	var categoryIDs []int
 	if categoryIDsStr != "" {
@@ -99,6 +115,21 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		groupID = &id
 	}
 
+	var draft bool
+	if draftStr := r.FormValue("draft"); draftStr != "" {
+		draft, err = strconv.ParseBool(draftStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid draft flag")
+			return
+		}
+	}
+
+	publishAt, err := getFormPublishAt(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid publish_at, expected RFC 3339")
+		return
+	}
+
 	if len(title) > 200 {
 		writeError(w, http.StatusBadRequest, "Title must be less than 200 characters")
 		return
@@ -153,7 +184,7 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		thumbnailURL = &url
 	}
 
-	post, err := h.postUsecase.CreatePost(user.ID, title, content, thumbnailURL, categoryIDs, groupID)
+	post, err := h.postUsecase.CreatePost(user.ID, title, content, contentFormat, thumbnailURL, categoryIDs, groupID, draft, publishAt)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -190,6 +221,12 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	contentFormat, err := domain.ParseContentFormat(r.FormValue("content_format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "content_format must be plain or markdown")
+		return
+	}
+
 	if len(title) > 200 {
 		writeError(w, http.StatusBadRequest, "Title must be less than 200 characters")
 		return
@@ -271,7 +308,35 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		groupID = &id
 	}
 
-	post, err := h.postUsecase.UpdatePost(user.ID, postID, title, content, thumbnailURL, categoryIDs, groupID)
+	publishAt, err := getFormPublishAt(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid publish_at, expected RFC 3339")
+		return
+	}
+
+	post, err := h.postUsecase.UpdatePost(user.ID, postID, title, content, contentFormat, thumbnailURL, categoryIDs, groupID, publishAt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) SubmitDraft(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	post, err := h.postUsecase.SubmitDraft(user.ID, postID)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -325,24 +390,119 @@ func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *PostHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var userID *int
+	user := GetUserFromContext(r.Context())
+	if user != nil {
+		userID = &user.ID
+	}
+
+	page, err := h.postUsecase.GetApprovedPosts(pageReq, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
+}
+
+// GetTagPosts lists posts with the {tag} hashtag
+func (h *PostHandler) GetTagPosts(w http.ResponseWriter, r *http.Request) {
+	tag, err := url.PathUnescape(chi.URLParam(r, "tag"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid tag")
+		return
+	}
+
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var userID *int
+	user := GetUserFromContext(r.Context())
+	if user != nil {
+		userID = &user.ID
+	}
+
+	page, err := h.postUsecase.GetTagPosts(tag, pageReq, userID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
+}
+
+func (h *PostHandler) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.postUsecase.GetTrendingTags(getQueryInt(r, "limit", 10))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (h *PostHandler) SearchPosts(w http.ResponseWriter, r *http.Request) {
 	page := getQueryInt(r, "page", 1)
 	limit := getQueryInt(r, "limit", 20)
 
+	var params domain.PostSearchParams
+	var err error
+	if params.CategoryID, err = getQueryOptionalInt(r, "category_id"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+	if params.AuthorID, err = getQueryOptionalInt(r, "author_id"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid author ID")
+		return
+	}
+	if params.GroupID, err = getQueryOptionalInt(r, "group_id"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	// A date without a time covers the whole day, so "to" is pushed to the
+	// start of the following day
+	from, _, err := getQueryTime(r, "from")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid from date")
+		return
+	}
+	until, dateOnly, err := getQueryTime(r, "to")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid to date")
+		return
+	}
+	if until != nil && dateOnly {
+		nextDay := until.AddDate(0, 0, 1)
+		until = &nextDay
+	}
+	params.From = from
+	params.Until = until
+
 	var userID *int
 	user := GetUserFromContext(r.Context())
 	if user != nil {
 		userID = &user.ID
 	}
 
-	posts, total, err := h.postUsecase.GetApprovedPosts(page, limit, userID)
+	results, total, err := h.postUsecase.SearchPosts(r.URL.Query().Get("q"), params, page, limit, userID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	response := PaginatedResponse{
-		Data:  posts,
-		Total: total,
+		Data:  results,
+		Total: &total,
 		Page:  page,
 		Limit: limit,
 	}
@@ -357,23 +517,68 @@ func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page := getQueryInt(r, "page", 1)
-	limit := getQueryInt(r, "limit", 20)
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	posts, total, err := h.postUsecase.GetUserPosts(user.ID, page, limit)
+	page, err := h.postUsecase.GetUserPosts(user.ID, pageReq)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response := PaginatedResponse{
-		Data:  posts,
-		Total: total,
-		Page:  page,
-		Limit: limit,
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
+}
+
+func (h *PostHandler) GetUserDrafts(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.postUsecase.GetUserDrafts(user.ID, pageReq)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
+}
+
+func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Counting evaluates the whole feed, so unlike other listings it is
+	// only done on request
+	if r.URL.Query().Get("include_total") == "" {
+		pageReq.WithTotal = false
+	}
+
+	page, err := h.postUsecase.GetFeed(user.ID, pageReq)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
 }
 
 func (h *PostHandler) CreateReply(w http.ResponseWriter, r *http.Request) {
@@ -400,7 +605,13 @@ func (h *PostHandler) CreateReply(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reply, err := h.postUsecase.CreateReply(user.ID, postID, req.Content, req.IsAnonymous)
+	contentFormat, err := domain.ParseContentFormat(req.ContentFormat)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reply, err := h.postUsecase.CreateReply(user.ID, postID, req.ParentReplyID, req.Content, contentFormat, req.IsAnonymous)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -409,6 +620,203 @@ func (h *PostHandler) CreateReply(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, reply)
 }
 
+func (h *PostHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	parentReplyID, err := getQueryOptionalInt(r, "parent_id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid parent_id")
+		return
+	}
+
+	pageReq, err := getPostPageRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var userID *int
+	user := GetUserFromContext(r.Context())
+	if user != nil {
+		userID = &user.ID
+	}
+
+	page, err := h.postUsecase.GetReplies(postID, parentReplyID, pageReq, userID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PaginatedResponse{
+		Data:       page.Replies,
+		Total:      page.Total,
+		Page:       pageReq.Page,
+		Limit:      pageReq.Limit,
+		NextCursor: encodeCursor(page.NextCursor),
+	})
+}
+
+func (h *PostHandler) UpdateReply(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	replyID, err := getIntParam(r, "replyId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid reply ID")
+		return
+	}
+
+	var req UpdateReplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentFormat, err := domain.ParseContentFormat(req.ContentFormat)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reply, err := h.postUsecase.UpdateReply(user.ID, postID, replyID, req.Content, contentFormat)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reply)
+}
+
+func (h *PostHandler) DeleteReply(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	replyID, err := getIntParam(r, "replyId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid reply ID")
+		return
+	}
+
+	err = h.postUsecase.DeleteReply(user.ID, postID, replyID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Revision handlers
+func (h *PostHandler) GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	revisions, err := h.postUsecase.GetPostRevisions(user.ID, postID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+func (h *PostHandler) DiffPostRevisions(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid from revision")
+		return
+	}
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid to revision")
+		return
+	}
+
+	diff, err := h.postUsecase.DiffPostRevisions(user.ID, postID, from, to)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, diff)
+}
+
+func (h *PostHandler) RestorePostRevision(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	revision, err := getIntParam(r, "revision")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	post, err := h.postUsecase.RestorePostRevision(user.ID, postID, revision)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, post)
+}
+
 // Category handlers
 func (h *PostHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
 	categories, err := h.postUsecase.GetAllCategories()
@@ -476,6 +884,91 @@ func (h *PostHandler) ToggleLike(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Reaction handlers
+func (h *PostHandler) AddPostReaction(w http.ResponseWriter, r *http.Request) {
+	h.handlePostReaction(w, r, h.postUsecase.AddPostReaction)
+}
+
+func (h *PostHandler) RemovePostReaction(w http.ResponseWriter, r *http.Request) {
+	h.handlePostReaction(w, r, h.postUsecase.RemovePostReaction)
+}
+
+func (h *PostHandler) AddReplyReaction(w http.ResponseWriter, r *http.Request) {
+	h.handleReplyReaction(w, r, h.postUsecase.AddReplyReaction)
+}
+
+func (h *PostHandler) RemoveReplyReaction(w http.ResponseWriter, r *http.Request) {
+	h.handleReplyReaction(w, r, h.postUsecase.RemoveReplyReaction)
+}
+
+func (h *PostHandler) handlePostReaction(w http.ResponseWriter, r *http.Request, apply func(userID, postID int, emoji string) error) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	emoji, err := getReactionParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid reaction")
+		return
+	}
+
+	err = apply(user.ID, postID, emoji)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PostHandler) handleReplyReaction(w http.ResponseWriter, r *http.Request, apply func(userID, postID, replyID int, emoji string) error) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	replyID, err := getIntParam(r, "replyId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid reply ID")
+		return
+	}
+
+	emoji, err := getReactionParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid reaction")
+		return
+	}
+
+	err = apply(user.ID, postID, replyID, emoji)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getReactionParam returns the {emoji} path segment, which may be percent
+// encoded when the configured reactions are emoji characters
+func getReactionParam(r *http.Request) (string, error) {
+	return url.PathUnescape(chi.URLParam(r, "emoji"))
+}
+
 // Group handlers
 func (h *PostHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
@@ -566,23 +1059,19 @@ func (h *PostHandler) GetGroupPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page := getQueryInt(r, "page", 1)
-	limit := getQueryInt(r, "limit", 20)
-
-	posts, total, err := h.postUsecase.GetGroupPosts(groupID, user.ID, page, limit)
+	pageReq, err := getPostPageRequest(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response := PaginatedResponse{
-		Data:  posts,
-		Total: total,
-		Page:  page,
-		Limit: limit,
+	page, err := h.postUsecase.GetGroupPosts(groupID, user.ID, pageReq)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	writeJSON(w, http.StatusOK, newPostPageResponse(pageReq, page))
 }
 
 func (h *PostHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
@@ -790,3 +1279,17 @@ func generateFilename(originalFilename string) string {
 	ext := filepath.Ext(originalFilename)
 	return strconv.FormatInt(time.Now().UnixNano(), 10) + ext
 }
+
+// getFormPublishAt parses the optional publish_at form value
+func getFormPublishAt(r *http.Request) (*time.Time, error) {
+	publishAtStr := r.FormValue("publish_at")
+	if publishAtStr == "" {
+		return nil, nil
+	}
+
+	publishAt, err := time.Parse(time.RFC3339, publishAtStr)
+	if err != nil {
+		return nil, err
+	}
+	return &publishAt, nil
+}
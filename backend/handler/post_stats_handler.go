@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"posting-app/usecase"
+)
+
+type PostStatsHandler struct {
+	postStatsUsecase *usecase.PostStatsUsecase
+}
+
+func NewPostStatsHandler(postStatsUsecase *usecase.PostStatsUsecase) *PostStatsHandler {
+	return &PostStatsHandler{
+		postStatsUsecase: postStatsUsecase,
+	}
+}
+
+func (h *PostStatsHandler) GetPostStats(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	postID, err := getIntParam(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	stats, err := h.postStatsUsecase.GetPostStats(user.ID, postID, getQueryInt(r, "days", 0))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *PostStatsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	summary, err := h.postStatsUsecase.GetSummary(user.ID, getQueryInt(r, "days", 0))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -7,24 +7,35 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
-	"posting-app/infrastructure"
+	"posting-app/domain"
+	"posting-app/usecase"
 )
 
 type Handlers struct {
-	Auth         *AuthHandler
-	Post         *PostHandler
-	Admin        *AdminHandler
-	Subscription *SubscriptionHandler
-	User         *UserHandler
+	Auth                *AuthHandler
+	Post                *PostHandler
+	Admin               *AdminHandler
+	Subscription        *SubscriptionHandler
+	User                *UserHandler
+	TwoFactor           *TwoFactorHandler
+	JWKS                *JWKSHandler
+	PersonalAccessToken *PersonalAccessTokenHandler
+	OIDC                *OIDCHandler
+	Session             *SessionHandler
+	Bookmark            *BookmarkHandler
+	Follow              *FollowHandler
+	Notification        *NotificationHandler
+	Event               *EventHandler
+	PostStats           *PostStatsHandler
 }
 
-func NewRouter(handlers *Handlers, jwtService *infrastructure.JWTService) http.Handler {
+func NewRouter(handlers *Handlers, authUsecase *usecase.AuthUsecase, clientIPResolver *ClientIPResolver) http.Handler {
 	r := chi.NewRouter()
 
 	// Middleware
+	r.Use(clientIPResolver.Middleware)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 
 	// CORS
 	r.Use(cors.Handler(cors.Options{
@@ -36,93 +47,207 @@ func NewRouter(handlers *Handlers, jwtService *infrastructure.JWTService) http.H
 		MaxAge:           300,
 	}))
 
-	// Public routes
-	r.Route("/auth", func(r chi.Router) {
-		r.Post("/register", handlers.Auth.Register)
-		r.Post("/login", handlers.Auth.Login)
-		r.Post("/forgot-password", handlers.Auth.ForgotPassword)
-		r.Post("/reset-password", handlers.Auth.ResetPassword)
+	// Event stream. It stays open for minutes, so it is kept out of the
+	// request timeout below.
+	r.Group(func(r chi.Router) {
+		r.Use(AuthMiddleware(authUsecase))
+		r.With(RequireScope(domain.TokenScopePostsRead)).Get("/events", handlers.Event.Stream)
 	})
 
-	// Admin auth
-	r.Post("/admin/login", handlers.Admin.Login)
-
-	// Subscription webhook (public)
-	r.Post("/subscription/webhook", handlers.Subscription.HandleWebhook)
-
-	// Serve uploaded files
-	r.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir("uploads/"))))
-
-	// Protected routes
 	r.Group(func(r chi.Router) {
-		r.Use(AuthMiddleware(jwtService))
-
-		// Auth
-		r.Post("/auth/logout", handlers.Auth.Logout)
-
-		// User routes
-		r.Route("/user", func(r chi.Router) {
-			r.Get("/profile", handlers.User.GetProfile)
-			r.Put("/profile", handlers.User.UpdateProfile)
-			r.Post("/change-password", handlers.Auth.ChangePassword)
-			r.Post("/deactivate", handlers.User.Deactivate)
-			r.Get("/posts", handlers.Post.GetUserPosts)
-		})
+		r.Use(middleware.Timeout(60 * time.Second))
 
-		// Post routes
-		r.Route("/posts", func(r chi.Router) {
-			r.Get("/", handlers.Post.GetPosts)
-			r.Post("/", handlers.Post.CreatePost)
-			r.Get("/{id}", handlers.Post.GetPost)
-			r.Put("/{id}", handlers.Post.UpdatePost)
-			r.Delete("/{id}", handlers.Post.DeletePost)
-			r.Post("/{id}/replies", handlers.Post.CreateReply)
-			r.Post("/{id}/like", handlers.Post.ToggleLike)
+		// Public routes
+		r.Route("/auth", func(r chi.Router) {
+			r.Post("/register", handlers.Auth.Register)
+			r.Post("/login", handlers.Auth.Login)
+			r.Post("/refresh", handlers.Auth.RefreshToken)
+			r.Post("/verify-email", handlers.Auth.VerifyEmail)
+			r.Post("/resend-verification", handlers.Auth.ResendVerification)
+			r.Post("/forgot-password", handlers.Auth.ForgotPassword)
+			r.Post("/reset-password", handlers.Auth.ResetPassword)
+			r.Post("/2fa/verify", handlers.Auth.VerifyTwoFactor)
+			r.Post("/unlock", handlers.Auth.UnlockAccount)
+			r.Get("/oidc/authorize", handlers.OIDC.Authorize)
+			r.Post("/oidc/callback", handlers.OIDC.Callback)
 		})
 
-		// Category routes
-		r.Route("/categories", func(r chi.Router) {
-			r.Get("/", handlers.Post.GetCategories)
-			r.Post("/", handlers.Post.CreateCategory) // Admin only, but we'll handle auth in handler
-		})
+		// Public keys for verifying tokens
+		r.Get("/.well-known/jwks.json", handlers.JWKS.GetJWKS)
 
-		// Group routes
-		r.Route("/groups", func(r chi.Router) {
-			r.Get("/", handlers.Post.GetUserGroups)
-			r.Post("/", handlers.Post.CreateGroup)
-			
-			// More specific routes first
-			r.Post("/{id}/members/by-name", handlers.Post.AddGroupMemberByDisplayName)
-			r.Delete("/{id}/members/{memberId}", handlers.Post.RemoveGroupMember)
-			r.Get("/{id}/members", handlers.Post.GetGroupMembers)
-			r.Post("/{id}/leave", handlers.Post.LeaveGroup)
-			r.Get("/{id}/posts", handlers.Post.GetGroupPosts)
-			
-			// General routes last
-			r.Put("/{id}", handlers.Post.UpdateGroup)
-			r.Delete("/{id}", handlers.Post.DeleteGroup)
-			// r.Post("/{id}/members", handlers.Post.AddGroupMember)
-		})
+		// Admin auth
+		r.Post("/admin/login", handlers.Admin.Login)
 
-		// User search routes
-		r.Get("/users/search", handlers.Post.SearchUsers)
+		// Subscription webhook (public)
+		r.Post("/subscription/webhook", handlers.Subscription.HandleWebhook)
 
-		// Subscription routes
-		r.Route("/subscription", func(r chi.Router) {
-			r.Get("/status", handlers.Subscription.GetStatus)
-			r.Post("/create-checkout-session", handlers.Subscription.CreateCheckoutSession)
-		})
+		// Serve uploaded files
+		r.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir("uploads/"))))
 
-		// Admin routes
+		// Protected routes
 		r.Group(func(r chi.Router) {
-			r.Use(AdminMiddleware)
-
-			r.Route("/admin", func(r chi.Router) {
-				r.Get("/posts", handlers.Admin.GetPosts)
-				r.Post("/posts/{id}/approve", handlers.Admin.ApprovePost)
-				r.Post("/posts/{id}/reject", handlers.Admin.RejectPost)
-				r.Get("/users", handlers.Admin.GetUsers)
-				r.Post("/users/{id}/ban", handlers.Admin.BanUser)
+			r.Use(AuthMiddleware(authUsecase))
+
+			// Auth
+			r.With(SessionOnly).Post("/auth/logout", handlers.Auth.Logout)
+
+			// User routes
+			r.Route("/user", func(r chi.Router) {
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/posts", handlers.Post.GetUserPosts)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/posts/stats", handlers.PostStats.GetSummary)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/posts/{id}/stats", handlers.PostStats.GetPostStats)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/drafts", handlers.Post.GetUserDrafts)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/bookmarks", handlers.Bookmark.GetBookmarks)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/bookmarks/collections", handlers.Bookmark.GetCollections)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Delete("/bookmarks/collections/{id}", handlers.Bookmark.DeleteCollection)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/following/categories", handlers.Follow.GetFollowedCategories)
+
+				r.Group(func(r chi.Router) {
+					r.Use(SessionOnly)
+
+					r.Get("/profile", handlers.User.GetProfile)
+					r.Put("/profile", handlers.User.UpdateProfile)
+					r.Post("/change-password", handlers.Auth.ChangePassword)
+					r.Post("/deactivate", handlers.User.Deactivate)
+
+					r.Route("/2fa", func(r chi.Router) {
+						r.Get("/", handlers.TwoFactor.GetStatus)
+						r.Post("/enroll", handlers.TwoFactor.Enroll)
+						r.Post("/confirm", handlers.TwoFactor.Confirm)
+						r.Post("/disable", handlers.TwoFactor.Disable)
+						r.Post("/recovery-codes", handlers.TwoFactor.RegenerateRecoveryCodes)
+					})
+
+					r.Route("/sessions", func(r chi.Router) {
+						r.Get("/", handlers.Session.GetSessions)
+						r.Post("/revoke-others", handlers.Session.RevokeOtherSessions)
+						r.Delete("/{id}", handlers.Session.RevokeSession)
+					})
+
+					r.Route("/tokens", func(r chi.Router) {
+						r.Get("/", handlers.PersonalAccessToken.GetTokens)
+						r.Post("/", handlers.PersonalAccessToken.CreateToken)
+						r.Delete("/{id}", handlers.PersonalAccessToken.RevokeToken)
+					})
+				})
+			})
+
+			// Home feed
+			r.With(RequireScope(domain.TokenScopePostsRead)).Get("/feed", handlers.Post.GetFeed)
+
+			// Notification routes
+			r.Route("/notifications", func(r chi.Router) {
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/", handlers.Notification.GetNotifications)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/unread-count", handlers.Notification.GetUnreadCount)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Post("/read-all", handlers.Notification.MarkAllRead)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Post("/{id}/read", handlers.Notification.MarkRead)
+				r.With(SessionOnly).Get("/preferences", handlers.Notification.GetPreferences)
+				r.With(SessionOnly).Put("/preferences", handlers.Notification.UpdatePreferences)
+			})
+
+			// Post routes
+			r.Route("/posts", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(RequireScope(domain.TokenScopePostsRead))
+
+					r.Get("/", handlers.Post.GetPosts)
+					r.Get("/search", handlers.Post.SearchPosts)
+					r.Get("/{id}", handlers.Post.GetPost)
+					r.Get("/{id}/replies", handlers.Post.GetReplies)
+					r.Get("/{id}/revisions", handlers.Post.GetPostRevisions)
+					r.Get("/{id}/revisions/diff", handlers.Post.DiffPostRevisions)
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(RequireScope(domain.TokenScopePostsWrite))
+
+					r.Post("/", handlers.Post.CreatePost)
+					r.Put("/{id}", handlers.Post.UpdatePost)
+					r.Post("/{id}/submit", handlers.Post.SubmitDraft)
+					r.Delete("/{id}", handlers.Post.DeletePost)
+					r.Post("/{id}/replies", handlers.Post.CreateReply)
+					r.Put("/{id}/replies/{replyId}", handlers.Post.UpdateReply)
+					r.Delete("/{id}/replies/{replyId}", handlers.Post.DeleteReply)
+					r.Post("/{id}/like", handlers.Post.ToggleLike)
+					r.Post("/{id}/bookmark", handlers.Bookmark.Bookmark)
+					r.Delete("/{id}/bookmark", handlers.Bookmark.RemoveBookmark)
+					r.Put("/{id}/reactions/{emoji}", handlers.Post.AddPostReaction)
+					r.Delete("/{id}/reactions/{emoji}", handlers.Post.RemovePostReaction)
+					r.Put("/{id}/replies/{replyId}/reactions/{emoji}", handlers.Post.AddReplyReaction)
+					r.Delete("/{id}/replies/{replyId}/reactions/{emoji}", handlers.Post.RemoveReplyReaction)
+					r.Post("/{id}/revisions/{revision}/restore", handlers.Post.RestorePostRevision)
+				})
+			})
+
+			// Category routes
+			r.Route("/categories", func(r chi.Router) {
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/", handlers.Post.GetCategories)
+				r.With(SessionOnly, AdminMiddleware).Post("/", handlers.Post.CreateCategory)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Post("/{id}/follow", handlers.Follow.FollowCategory)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Delete("/{id}/follow", handlers.Follow.UnfollowCategory)
+			})
+
+			// Hashtag routes
+			r.Route("/tags", func(r chi.Router) {
+				r.Use(RequireScope(domain.TokenScopePostsRead))
+
+				r.Get("/trending", handlers.Post.GetTrendingTags)
+				r.Get("/{tag}/posts", handlers.Post.GetTagPosts)
+			})
+
+			// Group routes
+			r.Route("/groups", func(r chi.Router) {
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/{id}/posts", handlers.Post.GetGroupPosts)
+
+				r.Group(func(r chi.Router) {
+					r.Use(RequireScope(domain.TokenScopeGroupsManage))
+
+					r.Get("/", handlers.Post.GetUserGroups)
+					r.Post("/", handlers.Post.CreateGroup)
+
+					// More specific routes first
+					r.Post("/{id}/members/by-name", handlers.Post.AddGroupMemberByDisplayName)
+					r.Delete("/{id}/members/{memberId}", handlers.Post.RemoveGroupMember)
+					r.Get("/{id}/members", handlers.Post.GetGroupMembers)
+					r.Post("/{id}/leave", handlers.Post.LeaveGroup)
+
+					// General routes last
+					r.Put("/{id}", handlers.Post.UpdateGroup)
+					r.Delete("/{id}", handlers.Post.DeleteGroup)
+					// r.Post("/{id}/members", handlers.Post.AddGroupMember)
+				})
+			})
+
+			// User search and follow routes
+			r.Route("/users", func(r chi.Router) {
+				r.With(RequireScope(domain.TokenScopeGroupsManage)).Get("/search", handlers.Post.SearchUsers)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/{id}/followers", handlers.Follow.GetFollowers)
+				r.With(RequireScope(domain.TokenScopePostsRead)).Get("/{id}/following", handlers.Follow.GetFollowing)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Post("/{id}/follow", handlers.Follow.FollowUser)
+				r.With(RequireScope(domain.TokenScopePostsWrite)).Delete("/{id}/follow", handlers.Follow.UnfollowUser)
+			})
+
+			// Subscription routes
+			r.Route("/subscription", func(r chi.Router) {
+				r.Use(SessionOnly)
+
+				r.Get("/status", handlers.Subscription.GetStatus)
+				r.Post("/create-checkout-session", handlers.Subscription.CreateCheckoutSession)
+			})
+
+			// Admin routes
+			r.Group(func(r chi.Router) {
+				r.Use(SessionOnly)
+				r.Use(AdminMiddleware)
+
+				r.Route("/admin", func(r chi.Router) {
+					r.Get("/posts", handlers.Admin.GetPosts)
+					r.Post("/posts/{id}/approve", handlers.Admin.ApprovePost)
+					r.Post("/posts/{id}/reject", handlers.Admin.RejectPost)
+					r.Get("/users", handlers.Admin.GetUsers)
+					r.Post("/users/{id}/ban", handlers.Admin.BanUser)
+					r.Post("/users/{id}/unlock", handlers.Admin.UnlockUser)
+				})
 			})
 		})
 	})
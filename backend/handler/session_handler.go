@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"posting-app/domain"
+	"posting-app/usecase"
+)
+
+type SessionHandler struct {
+	authUsecase *usecase.AuthUsecase
+}
+
+func NewSessionHandler(authUsecase *usecase.AuthUsecase) *SessionHandler {
+	return &SessionHandler{
+		authUsecase: authUsecase,
+	}
+}
+
+type SessionResponse struct {
+	domain.Session
+	Current bool `json:"current"`
+}
+
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.authUsecase.GetSessions(principal.User.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			Session: session,
+			Current: session.ID == principal.SessionID,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	err := h.authUsecase.RevokeSession(principal.User.ID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions logs out everywhere except the session making the request
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	principal := GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	err := h.authUsecase.RevokeOtherSessions(principal.User.ID, principal.SessionID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{
+		Message: "Other sessions revoked successfully",
+	})
+}
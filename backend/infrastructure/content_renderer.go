@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"bytes"
+	"html"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"posting-app/domain"
+)
+
+// markdown renders GitHub flavoured Markdown. Raw HTML in the source is
+// omitted rather than passed through, and single newlines become <br> as
+// they do in plain text.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.CJK),
+	goldmark.WithRendererOptions(goldmarkhtml.WithHardWraps()),
+)
+
+// contentPolicy is applied to every rendered body. It keeps formatting
+// elements, drops scripts, styles, event handlers and iframes, and only
+// allows http, https and mailto URLs. Links get rel="nofollow noopener".
+var contentPolicy = newContentPolicy()
+
+func newContentPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return policy
+}
+
+// RenderContent converts a post or reply body to sanitized HTML. The
+// sanitizer runs on the output of both formats, so a bug in the Markdown
+// renderer cannot introduce markup the policy does not allow.
+func RenderContent(format domain.ContentFormat, content string) (string, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var rendered string
+	switch format {
+	case domain.ContentFormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(content), &buf); err != nil {
+			return "", err
+		}
+		rendered = buf.String()
+	default:
+		rendered = renderPlainText(content)
+	}
+
+	return contentPolicy.Sanitize(rendered), nil
+}
+
+// renderPlainText escapes content and keeps its line breaks
+func renderPlainText(content string) string {
+	if content == "" {
+		return ""
+	}
+	escaped := html.EscapeString(content)
+	return "<p>" + strings.ReplaceAll(escaped, "\n", "<br>\n") + "</p>"
+}
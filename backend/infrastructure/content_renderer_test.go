@@ -0,0 +1,122 @@
+package infrastructure
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"posting-app/domain"
+)
+
+// FuzzRenderContent checks that no input, in either format, renders to
+// markup that can run script: no script elements, no event handler
+// attributes and no links or sources outside http, https, mailto and
+// relative URLs
+func FuzzRenderContent(f *testing.F) {
+	seeds := []string{
+		"<script>alert(1)</script>",
+		"<SCRIPT SRC=https://evil.example/x.js></SCRIPT>",
+		"<scr<script>ipt>alert(1)</script>",
+		"[click](javascript:alert(1))",
+		"[click](JaVaScRiPt:alert(1))",
+		"[click](java\tscript:alert(1))",
+		"[click](&#106;avascript:alert(1))",
+		"<javascript:alert(1)>",
+		"![img](javascript:alert(1))",
+		"[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)",
+		"![img](data:image/svg+xml,<svg onload=alert(1)>)",
+		"[click](vbscript:msgbox(1))",
+		"<img src=x onerror=alert(1)>",
+		"<a href=\"https://example.com\" onclick=\"alert(1)\">x</a>",
+		"<svg><animate onbegin=alert(1) attributeName=x dur=1s>",
+		"<div style=\"background:url(javascript:alert(1))\">x</div>",
+		"<iframe src=\"https://evil.example\"></iframe>",
+		"<details open ontoggle=alert(1)>",
+		"<a href=\"javascript:alert(1)\">raw html link</a>",
+		"<!-- <script>alert(1)</script> -->",
+		"<<script>script>alert(1)<</script>/script>",
+		"https://example.com/?q=<script>alert(1)</script>",
+		"www.example.com/\"onmouseover=\"alert(1)",
+		"user@example.com",
+		"mailto:user@example.com",
+		"[link](https://example.com \"title\\\" onmouseover=\\\"alert(1)\")",
+		"[ref]\n\n[ref]: javascript:alert(1)",
+		"`<script>alert(1)</script>`",
+		"```html\n<script>alert(1)</script>\n```",
+		"| a | <script>alert(1)</script> |\n|---|---|\n| b | c |",
+		"line one\r\nline two <b onmouseover=alert(1)>bold</b>",
+		"[relative](/posts/1) and [fragment](#top) and [query](?page=2)",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		for _, format := range []domain.ContentFormat{domain.ContentFormatPlain, domain.ContentFormatMarkdown} {
+			rendered, err := RenderContent(format, content)
+			if err != nil {
+				continue
+			}
+			if problem := unsafeMarkup(rendered); problem != "" {
+				t.Fatalf("%s content %q rendered to %q: %s", format, content, rendered, problem)
+			}
+		}
+	})
+}
+
+// unsafeMarkup describes the first script-capable construct in rendered, or
+// returns "" if there is none
+func unsafeMarkup(rendered string) string {
+	if strings.Contains(strings.ToLower(rendered), "<script") {
+		return "contains <script"
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(rendered))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return ""
+			}
+			return "unparsable output: " + tokenizer.Err().Error()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "script" {
+				return "script element"
+			}
+			for _, attr := range token.Attr {
+				key := strings.ToLower(attr.Key)
+				if strings.HasPrefix(key, "on") {
+					return "event handler attribute " + attr.Key
+				}
+				if (key == "href" || key == "src") && !isAllowedURL(attr.Val) {
+					return key + " " + attr.Val
+				}
+			}
+		}
+	}
+}
+
+// isAllowedURL reports whether value is relative or uses http, https or
+// mailto. Like browsers, it ignores tabs and newlines and leading control
+// characters and spaces before looking for the scheme.
+func isAllowedURL(value string) bool {
+	value = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, value)
+	value = strings.TrimLeft(value, "\x00\x01\x02\x03\x04\x05\x06\x07\x08\x0b\x0c\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f ")
+
+	end := strings.IndexAny(value, ":/?#")
+	if end < 0 || value[end] != ':' {
+		return true
+	}
+	switch strings.ToLower(value[:end]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
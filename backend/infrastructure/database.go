@@ -17,11 +17,14 @@ type Config struct {
 	SSLMode  string `envconfig:"DB_SSLMODE" default:"disable"`
 }
 
-func NewDatabase(config Config) (*sql.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+// DSN is the lib/pq connection string for config
+func (c Config) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode)
+}
 
-	db, err := sql.Open("postgres", dsn)
+func NewDatabase(config Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
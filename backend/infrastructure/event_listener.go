@@ -0,0 +1,136 @@
+package infrastructure
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UserEventsChannel is the channel the user_events trigger notifies, with
+// the recipient's user ID as payload
+const UserEventsChannel = "user_events"
+
+// listenerPingInterval keeps the listening connection checked while no
+// notifications arrive
+const listenerPingInterval = 90 * time.Second
+
+// EventListener listens for user event notifications from PostgreSQL and
+// wakes the subscribers of the recipient. Because every instance listens,
+// an event written by any instance reaches streams open on all of them.
+type EventListener struct {
+	listener *pq.Listener
+
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{}
+}
+
+func NewEventListener(config Config) (*EventListener, error) {
+	l := &EventListener{
+		subscribers: make(map[int]map[chan struct{}]struct{}),
+	}
+
+	l.listener = pq.NewListener(config.DSN(), 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("Event listener connection error", "event", event, "error", err)
+		}
+	})
+
+	if err := l.listener.Listen(UserEventsChannel); err != nil {
+		l.listener.Close()
+		return nil, fmt.Errorf("failed to listen for user events: %w", err)
+	}
+
+	go l.run()
+
+	slog.Info("Listening for user events", "channel", UserEventsChannel)
+	return l, nil
+}
+
+// Subscribe returns a channel that receives a value whenever userID may
+// have new events, and a function that ends the subscription. Wakeups that
+// arrive before the previous one was received are merged, so subscribers
+// must read all events they have not seen yet on each one.
+func (l *EventListener) Subscribe(userID int) (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	l.mu.Lock()
+	if l.subscribers[userID] == nil {
+		l.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	l.subscribers[userID][wake] = struct{}{}
+	l.mu.Unlock()
+
+	unsubscribe := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers[userID], wake)
+		if len(l.subscribers[userID]) == 0 {
+			delete(l.subscribers, userID)
+		}
+	}
+	return wake, unsubscribe
+}
+
+func (l *EventListener) Close() error {
+	return l.listener.Close()
+}
+
+func (l *EventListener) run() {
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case notification, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification follows a reconnect, during which
+			// notifications may have been lost
+			if notification == nil {
+				l.wakeAll()
+				continue
+			}
+			userID, err := strconv.Atoi(notification.Extra)
+			if err != nil {
+				slog.Error("Invalid user event notification", "payload", notification.Extra)
+				continue
+			}
+			l.wake(userID)
+		case <-ping.C:
+			go func() {
+				if err := l.listener.Ping(); err != nil {
+					slog.Error("Event listener ping failed", "error", err)
+				}
+			}()
+		}
+	}
+}
+
+func (l *EventListener) wake(userID int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for wake := range l.subscribers[userID] {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *EventListener) wakeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, subscribers := range l.subscribers {
+		for wake := range subscribers {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
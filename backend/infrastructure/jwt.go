@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -9,66 +11,199 @@ import (
 	"posting-app/domain"
 )
 
+// JWTConfig selects how tokens are signed. With RS256 or EdDSA, tokens carry
+// a kid header and any key in VerificationKeyFiles is accepted as well, so a
+// retired signing key can stay valid until its tokens expire. Setting Secret
+// alongside an asymmetric algorithm keeps accepting HS256 tokens issued before
+// the switch.
 type JWTConfig struct {
-	Secret               string        `envconfig:"JWT_SECRET" required:"true"`
+	Secret               string        `envconfig:"JWT_SECRET"`
+	Algorithm            string        `envconfig:"JWT_ALGORITHM" default:"HS256"`
+	SigningKeyFile       string        `envconfig:"JWT_SIGNING_KEY_FILE"`
+	VerificationKeyFiles []string      `envconfig:"JWT_VERIFICATION_KEY_FILES"`
 	AccessTokenDuration  time.Duration `envconfig:"JWT_ACCESS_DURATION" default:"15m"`
 	RefreshTokenDuration time.Duration `envconfig:"JWT_REFRESH_DURATION" default:"720h"` // 30 days
 }
 
 type JWTService struct {
-	config JWTConfig
+	config           JWTConfig
+	signingMethod    jwt.SigningMethod
+	signingKey       interface{}
+	signingKeyID     string
+	verificationKeys []*verificationKey
 }
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeMFA     = "mfa"
+)
+
+const tokenIssuer = "posting-app"
+
+// tokenKinds gives each token type its own audience and JOSE typ header.
+// All types are signed with the same published key, so a verifier that only
+// knows the JWKS would otherwise take a refresh or MFA token for an access
+// token; the token_type claim is private to this service.
+var tokenKinds = map[string]struct {
+	audience string
+	typ      string
+}{
+	TokenTypeAccess:  {audience: "posting-app", typ: "at+jwt"},
+	TokenTypeRefresh: {audience: "posting-app:refresh", typ: "refresh+jwt"},
+	TokenTypeMFA:     {audience: "posting-app:mfa", typ: "mfa+jwt"},
+}
+
+// mfaTokenDuration bounds how long a password-verified login may wait for its
+// second factor
+const mfaTokenDuration = 5 * time.Minute
+
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID       int    `json:"user_id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	TokenType    string `json:"token_type,omitempty"`
+	TokenVersion int    `json:"ver"`
+	SessionID    string `json:"sid,omitempty"` // refresh token family the access token belongs to
+	MFA          bool   `json:"mfa,omitempty"` // second factor was verified at login
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(config JWTConfig) *JWTService {
-	return &JWTService{config: config}
+func NewJWTService(config JWTConfig) (*JWTService, error) {
+	service := &JWTService{config: config}
+
+	switch config.Algorithm {
+	case JWTAlgorithmHS256:
+		if config.Secret == "" {
+			return nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		service.signingMethod = jwt.SigningMethodHS256
+		service.signingKey = []byte(config.Secret)
+	case JWTAlgorithmRS256, JWTAlgorithmEdDSA:
+		if config.SigningKeyFile == "" {
+			return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE is required for %s", config.Algorithm)
+		}
+		privateKey, key, err := loadSigningKey(config.Algorithm, config.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		service.signingMethod = key.method
+		service.signingKey = privateKey
+		service.signingKeyID = key.kid
+		service.verificationKeys = append(service.verificationKeys, key)
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", config.Algorithm)
+	}
+
+	for _, path := range config.VerificationKeyFiles {
+		key, err := loadVerificationKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if service.findVerificationKey(key.kid) == nil {
+			service.verificationKeys = append(service.verificationKeys, key)
+		}
+	}
+
+	return service, nil
 }
 
-func (j *JWTService) GenerateAccessToken(user *domain.User) (string, error) {
+func (j *JWTService) GenerateAccessToken(user *domain.User, sessionID string, mfa bool) (string, error) {
+	jti, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   string(user.Role),
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         string(user.Role),
+		TokenType:    TokenTypeAccess,
+		TokenVersion: user.TokenVersion,
+		SessionID:    sessionID,
+		MFA:          mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.config.AccessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "posting-app",
+			Issuer:    tokenIssuer,
+			ID:        jti,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.config.Secret))
+	return j.sign(claims)
 }
 
-func (j *JWTService) GenerateRefreshToken(user *domain.User) (string, error) {
+func (j *JWTService) GenerateRefreshToken(user *domain.User, mfa bool) (string, error) {
+	// Every refresh token gets a unique ID so that two tokens issued within
+	// the same second never share a hash
+	jti, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+
 	claims := Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   string(user.Role),
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         string(user.Role),
+		TokenType:    TokenTypeRefresh,
+		TokenVersion: user.TokenVersion,
+		MFA:          mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.config.RefreshTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "posting-app",
+			Issuer:    tokenIssuer,
+			ID:        jti,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.config.Secret))
+	return j.sign(claims)
 }
 
-func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.config.Secret), nil
-	})
+// GenerateMFAToken issues a short-lived token proving the password step of a
+// two-factor login. It cannot be used for API access.
+func (j *JWTService) GenerateMFAToken(user *domain.User) (string, error) {
+	jti, err := generateTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	claims := Claims{
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         string(user.Role),
+		TokenType:    TokenTypeMFA,
+		TokenVersion: user.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    tokenIssuer,
+			ID:        jti,
+		},
+	}
+
+	return j.sign(claims)
+}
+
+// sign sets the audience and typ header of the claims' token type and signs
+// the token
+func (j *JWTService) sign(claims Claims) (string, error) {
+	kind := tokenKinds[claims.TokenType]
+	claims.Audience = jwt.ClaimStrings{kind.audience}
+
+	token := jwt.NewWithClaims(j.signingMethod, claims)
+	token.Header["typ"] = kind.typ
+	if j.signingKeyID != "" {
+		token.Header["kid"] = j.signingKeyID
+	}
+	return token.SignedString(j.signingKey)
+}
+
+// validateToken verifies a token and requires it to be of tokenType by its
+// audience, typ header and token_type claim
+func (j *JWTService) validateToken(tokenString, tokenType string) (*Claims, error) {
+	kind := tokenKinds[tokenType]
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey,
+		jwt.WithAudience(kind.audience), jwt.WithIssuer(tokenIssuer))
 
 	if err != nil {
 		return nil, err
@@ -79,5 +214,75 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if typ, _ := token.Header["typ"].(string); typ != kind.typ || claims.TokenType != tokenType {
+		return nil, errors.New("invalid token type")
+	}
+
 	return claims, nil
 }
+
+func (j *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
+	return j.validateToken(tokenString, TokenTypeAccess)
+}
+
+func (j *JWTService) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	return j.validateToken(tokenString, TokenTypeRefresh)
+}
+
+func (j *JWTService) ValidateMFAToken(tokenString string) (*Claims, error) {
+	return j.validateToken(tokenString, TokenTypeMFA)
+}
+
+// verificationKey picks the key for a token by its kid header. Tokens without
+// a kid are HS256 tokens and are only accepted while a secret is configured.
+func (j *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || j.config.Secret == "" {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(j.config.Secret), nil
+	}
+
+	key := j.findVerificationKey(kid)
+	if key == nil {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.publicKey, nil
+}
+
+func (j *JWTService) findVerificationKey(kid string) *verificationKey {
+	for _, key := range j.verificationKeys {
+		if key.kid == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// JWKS returns the public keys tokens can be verified with. It is empty when
+// only HS256 is in use.
+func (j *JWTService) JWKS() JWKSet {
+	keys := make([]JWK, 0, len(j.verificationKeys))
+	for _, key := range j.verificationKeys {
+		keys = append(keys, key.jwk)
+	}
+	return JWKSet{Keys: keys}
+}
+
+func (j *JWTService) RefreshTokenDuration() time.Duration {
+	return j.config.RefreshTokenDuration
+}
+
+func generateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
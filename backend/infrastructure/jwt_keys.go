@@ -0,0 +1,146 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey is a public key tokens may be verified with
+type verificationKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	publicKey crypto.PublicKey
+	jwk       JWK
+}
+
+func loadSigningKey(algorithm, path string) (crypto.PrivateKey, *verificationKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read JWT signing key: %w", err)
+	}
+
+	switch algorithm {
+	case JWTAlgorithmRS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse RSA signing key: %w", err)
+		}
+		key, err := newVerificationKey(&privateKey.PublicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return privateKey, key, nil
+	case JWTAlgorithmEdDSA:
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse Ed25519 signing key: %w", err)
+		}
+		key, err := newVerificationKey(privateKey.(ed25519.PrivateKey).Public())
+		if err != nil {
+			return nil, nil, err
+		}
+		return privateKey, key, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported JWT algorithm: %s", algorithm)
+	}
+}
+
+// loadVerificationKey reads an RSA or Ed25519 public key in PEM format
+func loadVerificationKey(path string) (*verificationKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT verification key: %w", err)
+	}
+
+	if publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return newVerificationKey(publicKey)
+	}
+
+	publicKey, err := jwt.ParseEdPublicKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT verification key %s: not an RSA or Ed25519 public key", path)
+	}
+
+	return newVerificationKey(publicKey)
+}
+
+// newVerificationKey derives the kid from the key's RFC 7638 thumbprint, so
+// the same key always gets the same kid no matter where it is loaded.
+func newVerificationKey(publicKey crypto.PublicKey) (*verificationKey, error) {
+	var (
+		jwk        JWK
+		method     jwt.SigningMethod
+		thumbprint interface{}
+	)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		jwk = JWK{Kty: "RSA", Use: "sig", Alg: JWTAlgorithmRS256, N: n, E: e}
+		method = jwt.SigningMethodRS256
+		// Members in lexicographic order as required by RFC 7638
+		thumbprint = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{e, "RSA", n}
+	case ed25519.PublicKey:
+		x := base64.RawURLEncoding.EncodeToString(key)
+		jwk = JWK{Kty: "OKP", Use: "sig", Alg: JWTAlgorithmEdDSA, Crv: "Ed25519", X: x}
+		method = jwt.SigningMethodEdDSA
+		thumbprint = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{"Ed25519", "OKP", x}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	encoded, err := json.Marshal(thumbprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute key thumbprint: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	jwk.Kid = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return &verificationKey{
+		kid:       jwk.Kid,
+		method:    method,
+		publicKey: publicKey,
+		jwk:       jwk,
+	}, nil
+}
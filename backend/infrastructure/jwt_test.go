@@ -0,0 +1,94 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"posting-app/domain"
+)
+
+func TestJWTServiceTokenTypesAreNotInterchangeable(t *testing.T) {
+	service, err := NewJWTService(JWTConfig{
+		Secret:               "test-secret",
+		Algorithm:            JWTAlgorithmHS256,
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{ID: 1, Email: "user@example.com", Role: domain.UserRoleUser}
+
+	access, err := service.GenerateAccessToken(user, "session", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := service.GenerateRefreshToken(user, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfa, err := service.GenerateMFAToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validators := map[string]func(string) (*Claims, error){
+		TokenTypeAccess:  service.ValidateAccessToken,
+		TokenTypeRefresh: service.ValidateRefreshToken,
+		TokenTypeMFA:     service.ValidateMFAToken,
+	}
+	tokens := map[string]string{
+		TokenTypeAccess:  access,
+		TokenTypeRefresh: refresh,
+		TokenTypeMFA:     mfa,
+	}
+
+	for tokenType, token := range tokens {
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		audience, _ := parsed.Claims.GetAudience()
+		if len(audience) != 1 || audience[0] != tokenKinds[tokenType].audience {
+			t.Errorf("%s token: expected audience %s, got %v", tokenType, tokenKinds[tokenType].audience, audience)
+		}
+		if parsed.Header["typ"] != tokenKinds[tokenType].typ {
+			t.Errorf("%s token: expected typ %s, got %v", tokenType, tokenKinds[tokenType].typ, parsed.Header["typ"])
+		}
+
+		for validatorType, validate := range validators {
+			_, err := validate(token)
+			if validatorType == tokenType && err != nil {
+				t.Errorf("%s token rejected by its own validator: %v", tokenType, err)
+			}
+			if validatorType != tokenType && err == nil {
+				t.Errorf("%s token accepted as %s token", tokenType, validatorType)
+			}
+		}
+	}
+}
+
+func TestJWTServiceRejectsTokenWithoutAudience(t *testing.T) {
+	service, err := NewJWTService(JWTConfig{Secret: "test-secret", Algorithm: JWTAlgorithmHS256})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := Claims{
+		UserID:    1,
+		TokenType: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    tokenIssuer,
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.ValidateRefreshToken(token); err == nil {
+		t.Error("expected a token without audience to be rejected")
+	}
+}
@@ -0,0 +1,149 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const DefaultMailLanguage = "ja"
+
+const (
+	MailTemplatePasswordReset     = "password_reset"
+	MailTemplateEmailVerification = "email_verification"
+	MailTemplateAccountUnlock     = "account_unlock"
+)
+
+type mailTemplate struct {
+	subject string
+	body    string
+}
+
+// mailTemplates holds the message text per template name and language
+var mailTemplates = map[string]map[string]mailTemplate{
+	MailTemplatePasswordReset: {
+		"ja": {
+			subject: "【Posting App】パスワード再設定のご案内",
+			body: `{{.DisplayName}} 様
+
+パスワード再設定のリクエストを受け付けました。
+以下のリンクから1時間以内に新しいパスワードを設定してください。
+
+{{.URL}}
+
+このメールに心当たりがない場合は、破棄していただいて問題ありません。
+パスワードは変更されません。
+
+Posting App
+`,
+		},
+		"en": {
+			subject: "[Posting App] Reset your password",
+			body: `Hi {{.DisplayName}},
+
+We received a request to reset your password.
+Use the link below within 1 hour to choose a new password.
+
+{{.URL}}
+
+If you did not request this, you can safely ignore this email.
+Your password will not be changed.
+
+Posting App
+`,
+		},
+	},
+	MailTemplateEmailVerification: {
+		"ja": {
+			subject: "【Posting App】メールアドレスの確認",
+			body: `{{.DisplayName}} 様
+
+Posting App にご登録いただきありがとうございます。
+以下のリンクから24時間以内にメールアドレスを確認してください。
+
+{{.URL}}
+
+このメールに心当たりがない場合は、破棄していただいて問題ありません。
+
+Posting App
+`,
+		},
+		"en": {
+			subject: "[Posting App] Verify your email address",
+			body: `Hi {{.DisplayName}},
+
+Thanks for signing up for Posting App.
+Use the link below within 24 hours to verify your email address.
+
+{{.URL}}
+
+If you did not sign up, you can safely ignore this email.
+
+Posting App
+`,
+		},
+	},
+	MailTemplateAccountUnlock: {
+		"ja": {
+			subject: "【Posting App】アカウントが一時的にロックされました",
+			body: `{{.DisplayName}} 様
+
+ログインの失敗が続いたため、アカウントを一時的にロックしました。
+ご本人の操作であれば、以下のリンクから24時間以内にロックを解除できます。
+
+{{.URL}}
+
+心当たりがない場合は、第三者がログインを試みている可能性があります。
+パスワードの変更をおすすめします。
+
+Posting App
+`,
+		},
+		"en": {
+			subject: "[Posting App] Your account has been temporarily locked",
+			body: `Hi {{.DisplayName}},
+
+We temporarily locked your account after several failed sign-in attempts.
+If this was you, use the link below within 24 hours to unlock it.
+
+{{.URL}}
+
+If this was not you, someone may be trying to access your account.
+We recommend changing your password.
+
+Posting App
+`,
+		},
+	},
+}
+
+// RenderMail builds a mail from a named template. Unsupported languages fall
+// back to DefaultMailLanguage.
+func RenderMail(name, lang, to string, data interface{}) (*Mail, error) {
+	templates, ok := mailTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown mail template: %s", name)
+	}
+
+	tmpl, ok := templates[strings.ToLower(lang)]
+	if !ok {
+		tmpl = templates[DefaultMailLanguage]
+	}
+
+	body, err := template.New(name).Parse(tmpl.body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mail template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := body.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render mail template: %w", err)
+	}
+
+	return &Mail{
+		To:      to,
+		Subject: tmpl.subject,
+		Body:    buf.String(),
+	}, nil
+}
@@ -0,0 +1,127 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type MailConfig struct {
+	Driver       string `envconfig:"MAIL_DRIVER" default:"file"` // "smtp" or "file"
+	From         string `envconfig:"MAIL_FROM" default:"no-reply@posting-app.local"`
+	SMTPHost     string `envconfig:"SMTP_HOST"`
+	SMTPPort     int    `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD"`
+	OutboxDir    string `envconfig:"MAIL_OUTBOX_DIR" default:"outbox"`
+}
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain text mail. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(mail *Mail) error
+}
+
+func NewMailer(config MailConfig) (Mailer, error) {
+	switch config.Driver {
+	case "smtp":
+		if config.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail driver")
+		}
+		return NewSMTPMailer(config), nil
+	case "file":
+		return NewFileMailer(config)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", config.Driver)
+	}
+}
+
+// SMTPMailer sends mail through an SMTP relay
+type SMTPMailer struct {
+	config MailConfig
+}
+
+func NewSMTPMailer(config MailConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+func (m *SMTPMailer) Send(mail *Mail) error {
+	addr := m.config.SMTPHost + ":" + strconv.Itoa(m.config.SMTPPort)
+
+	var auth smtp.Auth
+	if m.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
+	}
+
+	err := smtp.SendMail(addr, auth, m.config.From, []string{mail.To}, buildMessage(m.config.From, mail))
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	slog.Info("Mail sent", "to", mail.To, "subject", mail.Subject)
+	return nil
+}
+
+// FileMailer writes each mail as an .eml file into an outbox directory.
+// It is meant for local development and tests.
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+func NewFileMailer(config MailConfig) (*FileMailer, error) {
+	if err := os.MkdirAll(config.OutboxDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mail outbox: %w", err)
+	}
+	return &FileMailer{from: config.From, dir: config.OutboxDir}, nil
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+func (m *FileMailer) Send(mail *Mail) error {
+	filename := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), unsafeFilenameChars.ReplaceAllString(mail.To, "_"))
+	path := filepath.Join(m.dir, filename)
+
+	err := os.WriteFile(path, buildMessage(m.from, mail), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write mail to outbox: %w", err)
+	}
+
+	slog.Info("Mail written to outbox", "to", mail.To, "subject", mail.Subject, "path", path)
+	return nil
+}
+
+func buildMessage(from string, mail *Mail) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + mail.To + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", mail.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	// Wrap base64 body at 76 characters per RFC 2045
+	encoded := base64.StdEncoding.EncodeToString([]byte(mail.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,350 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCConfig configures login through an external OpenID Connect provider.
+// Login is disabled while Issuer is empty.
+type OIDCConfig struct {
+	Issuer       string   `envconfig:"OIDC_ISSUER"`
+	ClientID     string   `envconfig:"OIDC_CLIENT_ID"`
+	ClientSecret string   `envconfig:"OIDC_CLIENT_SECRET"`
+	RedirectURL  string   `envconfig:"OIDC_REDIRECT_URL"`
+	Scopes       []string `envconfig:"OIDC_SCOPES" default:"openid,email,profile"`
+}
+
+// OIDCIDToken holds the ID token claims we use
+type OIDCIDToken struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string
+}
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcClaims struct {
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"` // some providers send "true" as a string
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+	Nonce             string      `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// OIDCProvider talks to the configured issuer. Discovery and keys are fetched
+// on first use so that an unreachable provider does not stop the server.
+type OIDCProvider struct {
+	config     OIDCConfig
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	metadata *oidcMetadata
+	keys     map[string]crypto.PublicKey
+}
+
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	return &OIDCProvider{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *OIDCProvider) Enabled() bool {
+	return p.config.Issuer != "" && p.config.ClientID != ""
+}
+
+func (p *OIDCProvider) Issuer() string {
+	return p.config.Issuer
+}
+
+// AuthorizationURL builds the URL the browser is sent to. codeChallenge is the
+// S256 PKCE challenge.
+func (p *OIDCProvider) AuthorizationURL(state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code and returns the verified ID token.
+// Checking the nonce is left to the caller.
+func (p *OIDCProvider) Exchange(code, codeVerifier string) (*OIDCIDToken, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	err = p.doJSON(req, &tokenResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("token response did not include an id_token")
+	}
+
+	return p.verifyIDToken(tokenResponse.IDToken, metadata)
+}
+
+func (p *OIDCProvider) verifyIDToken(rawIDToken string, metadata *oidcMetadata) (*OIDCIDToken, error) {
+	claims := &oidcClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, p.idTokenKey,
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if claims.Subject == "" || claims.ExpiresAt == nil {
+		return nil, errors.New("invalid id_token: missing subject or expiry")
+	}
+
+	emailVerified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		emailVerified = v
+	case string:
+		emailVerified = v == "true"
+	}
+
+	return &OIDCIDToken{
+		Issuer:            metadata.Issuer,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     emailVerified,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+		Nonce:             claims.Nonce,
+	}, nil
+}
+
+// idTokenKey finds the signing key by kid, refetching the provider's keys
+// once when the kid is unknown in case the provider rotated them.
+func (p *OIDCProvider) idTokenKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		keys, err := p.signingKeys(attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if kid == "" && len(keys) == 1 {
+			for _, key := range keys {
+				return key, nil
+			}
+		}
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown key id: %s", kid)
+}
+
+func (p *OIDCProvider) discover() (*oidcMetadata, error) {
+	if !p.Enabled() {
+		return nil, errors.New("OIDC login is not configured")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &oidcMetadata{}
+	err = p.doJSON(req, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("OIDC issuer mismatch: expected %s, got %s", p.config.Issuer, metadata.Issuer)
+	}
+
+	p.metadata = metadata
+	return metadata, nil
+}
+
+func (p *OIDCProvider) signingKeys(refresh bool) (map[string]crypto.PublicKey, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadata.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set JWKSet
+	err = p.doJSON(req, &set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip key types we do not support rather than failing the whole set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	p.keys = keys
+	return keys, nil
+}
+
+func (p *OIDCProvider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (k JWK) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
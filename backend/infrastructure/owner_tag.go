@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ownerTagSaltSize is the number of random bytes in each tag, so that two
+// tags of the same user cannot be linked to each other
+const ownerTagSaltSize = 16
+
+type OwnerTagConfig struct {
+	// Key is 32 random bytes, base64 encoded (e.g. `openssl rand -base64 32`)
+	Key string `envconfig:"ANONYMOUS_OWNER_KEY" required:"true"`
+}
+
+// OwnerTagger lets the author of anonymous content prove that it is theirs
+// without the content recording who wrote it. A tag is a random salt and an
+// HMAC-SHA256 of the salt and the user ID: it can be checked against a given
+// user, but not traced back to one without the key.
+type OwnerTagger struct {
+	key []byte
+}
+
+func NewOwnerTagger(config OwnerTagConfig) (*OwnerTagger, error) {
+	key, err := base64.StdEncoding.DecodeString(config.Key)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("ANONYMOUS_OWNER_KEY must be 32 bytes encoded in base64")
+	}
+	return &OwnerTagger{key: key}, nil
+}
+
+// Tag returns a new tag for userID
+func (t *OwnerTagger) Tag(userID int) (string, error) {
+	salt := make([]byte, ownerTagSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(salt) + "." + base64.RawURLEncoding.EncodeToString(t.mac(salt, userID)), nil
+}
+
+// Matches reports whether tag was made for userID
+func (t *OwnerTagger) Matches(tag string, userID int) bool {
+	encodedSalt, encodedMAC, ok := strings.Cut(tag, ".")
+	if !ok {
+		return false
+	}
+	salt, err := base64.RawURLEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(mac, t.mac(salt, userID))
+}
+
+func (t *OwnerTagger) mac(salt []byte, userID int) []byte {
+	h := hmac.New(sha256.New, t.key)
+	h.Write(salt)
+	h.Write([]byte(strconv.Itoa(userID)))
+	return h.Sum(nil)
+}
@@ -0,0 +1,41 @@
+package infrastructure
+
+import "testing"
+
+func TestOwnerTaggerMatchesOnlyItsUser(t *testing.T) {
+	tagger, err := NewOwnerTagger(OwnerTagConfig{Key: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewOwnerTagger(OwnerTagConfig{Key: "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := tagger.Tag(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := tagger.Tag(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tagger.Matches(tag, 42) {
+		t.Error("expected the tag to match its user")
+	}
+	if tagger.Matches(tag, 4) || tagger.Matches(tag, 420) {
+		t.Error("expected the tag not to match another user")
+	}
+	if other.Matches(tag, 42) {
+		t.Error("expected the tag not to match under another key")
+	}
+	if tag == again {
+		t.Error("expected two tags of the same user to differ")
+	}
+	for _, malformed := range []string{"", ".", "not-a-tag", tag + "x", "!" + tag} {
+		if tagger.Matches(malformed, 42) {
+			t.Errorf("expected malformed tag %q not to match", malformed)
+		}
+	}
+}
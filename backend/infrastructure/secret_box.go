@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// secretBoxPrefix marks values sealed by SecretBox, so that values written
+// before encryption was introduced can still be told apart and read
+const secretBoxPrefix = "v1:"
+
+type SecretBoxConfig struct {
+	// Key is 32 random bytes, base64 encoded (e.g. `openssl rand -base64 32`)
+	Key string `envconfig:"TOTP_ENCRYPTION_KEY" required:"true"`
+}
+
+// SecretBox encrypts small secrets, such as TOTP secrets, for storage with
+// AES-256-GCM. Each value is bound to a context string, typically the owning
+// row, so that a sealed value copied to another row does not open.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(config SecretBoxConfig) (*SecretBox, error) {
+	key, err := base64.StdEncoding.DecodeString(config.Key)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY must be 32 bytes encoded in base64")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts plaintext for the given context
+func (b *SecretBox) Seal(plaintext, context string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), []byte(context))
+	return secretBoxPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed for the given context
+func (b *SecretBox) Open(value, context string) (string, error) {
+	if !IsSealed(value) {
+		return "", errors.New("value is not sealed")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, secretBoxPrefix))
+	if err != nil || len(sealed) < b.aead.NonceSize() {
+		return "", errors.New("malformed sealed value")
+	}
+
+	nonce, ciphertext := sealed[:b.aead.NonceSize()], sealed[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, []byte(context))
+	if err != nil {
+		return "", errors.New("failed to decrypt sealed value")
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value was produced by Seal rather than stored in
+// plaintext
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, secretBoxPrefix)
+}
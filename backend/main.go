@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 	"posting-app/di"
@@ -32,7 +37,7 @@ func main() {
 	defer container.DB.Close()
 
 	// Setup router
-	router := handler.NewRouter(container.Handlers, container.JWTService)
+	router := handler.NewRouter(container.Handlers, container.AuthUsecase, container.ClientIPResolver)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -40,9 +45,27 @@ func main() {
 		port = "8080"
 	}
 
-	slog.Info("Starting server", "port", port)
-	if err := http.ListenAndServe(":"+port, router); err != nil {
-		slog.Error("Server failed to start", "error", err)
-		os.Exit(1)
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		slog.Info("Starting server", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server failed to start", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// On SIGTERM, finish in-flight requests and write the buffered post
+	// views before exiting. Event streams do not end on their own, so they
+	// are cut off after the timeout.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	slog.Info("Shutting down server")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Server did not shut down cleanly", "error", err)
 	}
+	container.ViewRecorder.Close()
 }
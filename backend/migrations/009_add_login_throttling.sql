@@ -0,0 +1,25 @@
+-- Failed login tracking. scope is 'account' (keyed by lower-cased email, so
+-- unknown addresses are throttled the same way) or 'ip'.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    scope VARCHAR(20) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (scope, key)
+);
+
+-- Account unlock tokens sent by email (tokens are stored hashed)
+CREATE TABLE IF NOT EXISTS account_unlocks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failed_at ON login_throttles(last_failed_at);
+CREATE INDEX IF NOT EXISTS idx_account_unlocks_user_id ON account_unlocks(user_id);
+CREATE INDEX IF NOT EXISTS idx_account_unlocks_expires_at ON account_unlocks(expires_at);
@@ -0,0 +1,24 @@
+-- External OpenID Connect identities linked to users
+CREATE TABLE IF NOT EXISTS identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(issuer, subject)
+);
+
+-- Pending OIDC logins, keyed by the hashed state parameter
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities(user_id);
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
@@ -0,0 +1,24 @@
+-- Login sessions. A session's id is the family_id shared by the refresh
+-- tokens of one login and the sid claim of its access tokens.
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing logins become sessions so their tokens keep working
+INSERT INTO sessions (id, user_id, last_seen_at, expires_at, created_at)
+SELECT family_id, user_id, MAX(created_at), MAX(expires_at), MIN(created_at)
+FROM refresh_tokens
+WHERE revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
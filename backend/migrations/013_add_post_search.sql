@@ -0,0 +1,15 @@
+-- Full-text post search. The tsvector uses the 'simple' configuration so
+-- space-separated words match without stemming; Japanese text has no word
+-- boundaries, so substring matches are served by trigram indexes instead.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+    ) STORED;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_posts_search_vector ON posts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_posts_title_trgm ON posts USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_posts_content_trgm ON posts USING GIN (content gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- Post listings page by (created_at, id) instead of OFFSET. These indexes
+-- match the ORDER BY of each listing so the next page is an index range scan.
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_author_id_created_at_id ON posts(author_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_group_id_created_at_id ON posts(group_id, created_at DESC, id DESC);
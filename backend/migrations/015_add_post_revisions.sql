@@ -0,0 +1,27 @@
+-- Post revisions. Every create, update and restore stores a snapshot of the
+-- post as saved, numbered per post from 1.
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    content TEXT NOT NULL,
+    thumbnail_url TEXT,
+    category_ids INTEGER[] NOT NULL DEFAULT '{}',
+    editor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    restored_from INTEGER,
+    -- Set on the revision that was current when the post was approved
+    approved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(post_id, revision)
+);
+
+-- Existing posts start with their current state as revision 1
+INSERT INTO post_revisions (post_id, revision, title, content, thumbnail_url, category_ids, editor_id, approved_at, created_at)
+SELECT p.id, 1, p.title, p.content, p.thumbnail_url,
+       COALESCE((SELECT ARRAY_AGG(pc.category_id ORDER BY pc.category_id) FROM post_categories pc WHERE pc.post_id = p.id), '{}'),
+       p.author_id,
+       CASE WHEN p.status = 'approved' THEN p.updated_at END,
+       p.updated_at
+FROM posts p
+ON CONFLICT (post_id, revision) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Drafts and scheduled publishing. Posts may now have status 'draft'.
+-- An approved post is visible once published_at is set: on approval when
+-- publish_at is empty or past, otherwise by the batch publisher job.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE;
+
+-- Posts approved before this migration are already public
+UPDATE posts SET published_at = updated_at WHERE status = 'approved' AND published_at IS NULL;
+
+-- Indexes
+CREATE INDEX IF NOT EXISTS idx_posts_publish_at ON posts(publish_at) WHERE published_at IS NULL;
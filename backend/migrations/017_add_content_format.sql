@@ -0,0 +1,23 @@
+-- Markdown support. content keeps the source as written; content_html is the
+-- sanitized HTML rendered by the server and is what clients should display.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS content_format VARCHAR(20) NOT NULL DEFAULT 'plain';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS content_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS content_format VARCHAR(20) NOT NULL DEFAULT 'plain';
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS content_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE post_revisions ADD COLUMN IF NOT EXISTS content_format VARCHAR(20) NOT NULL DEFAULT 'plain';
+
+-- Render existing plain text bodies the same way the server does: escape
+-- HTML and turn line breaks into <br>
+CREATE OR REPLACE FUNCTION render_plain_text(content TEXT) RETURNS TEXT AS $$
+    SELECT CASE WHEN content = '' THEN '' ELSE
+        '<p>' || REPLACE(
+            REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(content, E'\r\n', E'\n'),
+                '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;'),
+            E'\n', E'<br>\n') || '</p>'
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE posts SET content_html = render_plain_text(content) WHERE content_html = '';
+UPDATE replies SET content_html = render_plain_text(content) WHERE content_html = '';
+
+DROP FUNCTION render_plain_text(TEXT);
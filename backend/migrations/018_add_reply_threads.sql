@@ -0,0 +1,14 @@
+-- Threaded replies. depth is 0 for a reply to the post itself and one more
+-- than the parent's otherwise; the server caps it at REPLY_MAX_DEPTH.
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS parent_reply_id INTEGER REFERENCES replies(id) ON DELETE CASCADE;
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0;
+
+-- Edits and deletions. A deleted reply stays as a tombstone with its content
+-- cleared so that the replies under it keep their place in the thread.
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Paging through the replies to a post or to another reply, oldest first
+CREATE INDEX IF NOT EXISTS idx_replies_thread ON replies(post_id, parent_reply_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_replies_parent_reply_id ON replies(parent_reply_id) WHERE parent_reply_id IS NOT NULL;
@@ -0,0 +1,33 @@
+-- Emoji reactions on posts and replies. A user can add each reaction once;
+-- the set of allowed reactions is configured on the server (REACTIONS).
+CREATE TABLE IF NOT EXISTS post_reactions (
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, emoji)
+);
+
+CREATE TABLE IF NOT EXISTS reply_reactions (
+    reply_id INTEGER NOT NULL REFERENCES replies(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (reply_id, user_id, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_reactions_user_id ON post_reactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_reply_reactions_user_id ON reply_reactions(user_id);
+
+-- Likes become the default "like" reaction. likes_count and is_liked in the
+-- API are now derived from it.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'likes') THEN
+        INSERT INTO post_reactions (post_id, user_id, emoji, created_at)
+        SELECT post_id, user_id, 'like', created_at FROM likes
+        ON CONFLICT DO NOTHING;
+
+        DROP TABLE likes;
+    END IF;
+END $$;
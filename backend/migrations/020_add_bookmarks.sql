@@ -0,0 +1,23 @@
+-- Saved posts. A bookmark is kept when its post becomes invisible to the
+-- user (deleted, rejected, group membership lost); listings filter those out
+-- and show it again if the post comes back.
+CREATE TABLE IF NOT EXISTS bookmark_collections (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    collection_id INTEGER REFERENCES bookmark_collections(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, post_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created ON bookmarks(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_collection_id ON bookmarks(collection_id) WHERE collection_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bookmarks_post_id ON bookmarks(post_id);
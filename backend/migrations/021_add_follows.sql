@@ -0,0 +1,25 @@
+-- Follows between users and from users to categories, for the home feed
+CREATE TABLE IF NOT EXISTS user_follows (
+    follower_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE TABLE IF NOT EXISTS category_follows (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, category_id)
+);
+
+-- Follower and following lists, newest first
+CREATE INDEX IF NOT EXISTS idx_user_follows_follower_created ON user_follows(follower_id, created_at DESC, followee_id DESC);
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee_created ON user_follows(followee_id, created_at DESC, follower_id DESC);
+
+-- The feed walks published posts newest first and keeps those whose author,
+-- category or group the user follows, so its cost depends on how far back
+-- the page is rather than on how many accounts the user follows
+CREATE INDEX IF NOT EXISTS idx_posts_published_feed ON posts(created_at DESC, id DESC)
+    WHERE status = 'approved' AND is_deleted = false AND published_at IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Events pushed to users over the event stream (GET /events). Each row is
+-- for one recipient; ids only increase so that clients can resume with
+-- Last-Event-ID. Old events are removed by the prune-events batch job.
+CREATE TABLE IF NOT EXISTS user_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_events_user_id ON user_events(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_user_events_created_at ON user_events(created_at);
+
+-- Wake the stream of the recipient on every instance. The payload is only
+-- the user ID; listeners read the events themselves, and notifications for
+-- the same user in one transaction are delivered once.
+CREATE OR REPLACE FUNCTION notify_user_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('user_events', NEW.user_id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS user_events_notify ON user_events;
+CREATE TRIGGER user_events_notify
+    AFTER INSERT ON user_events
+    FOR EACH ROW EXECUTE FUNCTION notify_user_event();
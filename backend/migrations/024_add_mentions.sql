@@ -0,0 +1,18 @@
+-- @display_name mentions in posts and replies. reply_id is empty for
+-- mentions in the post itself. Offsets are in characters (code points) of
+-- the content. notified_at is set once the mentioned user was notified, so
+-- edits only notify newly mentioned users.
+CREATE TABLE IF NOT EXISTS mentions (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    reply_id INTEGER REFERENCES replies(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mentions_post_id ON mentions(post_id) WHERE reply_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_mentions_reply_id ON mentions(reply_id) WHERE reply_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_mentions_user_id ON mentions(user_id);
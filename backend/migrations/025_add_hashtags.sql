@@ -0,0 +1,14 @@
+-- #hashtags extracted from post content. Unlike categories they are free
+-- form and not managed by admins. Tags are stored normalized (NFKC, lower
+-- case) so that full-width and half-width spellings match.
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag ON post_tags(tag, post_id);
+
+-- Trending tags count posts published within a recent window
+CREATE INDEX IF NOT EXISTS idx_posts_published_at ON posts(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Post views, counted once per user and day (UTC). Views are buffered in
+-- memory and written in batches, so the latest few seconds may be missing.
+CREATE TABLE IF NOT EXISTS post_views (
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    viewed_on DATE NOT NULL,
+    PRIMARY KEY (post_id, viewed_on, user_id)
+);
+
+-- Daily likes and replies in the author statistics
+CREATE INDEX IF NOT EXISTS idx_post_reactions_post_created_at ON post_reactions(post_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_replies_post_created_at ON replies(post_id, created_at) WHERE is_deleted = false;
@@ -0,0 +1,4 @@
+-- TOTP secrets are now stored encrypted (TOTP_ENCRYPTION_KEY), which makes
+-- them longer than the plaintext. Secrets saved before are encrypted the
+-- next time they are used.
+ALTER TABLE two_factor_credentials ALTER COLUMN secret TYPE TEXT;
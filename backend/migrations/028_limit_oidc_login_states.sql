@@ -0,0 +1,5 @@
+-- Logins in progress are counted per client IP, so that the unauthenticated
+-- authorize endpoint cannot fill oidc_login_states without bound
+ALTER TABLE oidc_login_states ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_ip_address ON oidc_login_states(ip_address, expires_at);
@@ -0,0 +1,9 @@
+-- Listings of published posts are ordered and paged by (published_at, id),
+-- so that scheduled posts appear at the top once published. The feed index
+-- by created_at is replaced; author and moderation listings keep using the
+-- created_at indexes of 014.
+DROP INDEX IF EXISTS idx_posts_published_feed;
+CREATE INDEX IF NOT EXISTS idx_posts_published_feed ON posts(published_at DESC, id DESC)
+    WHERE status = 'approved' AND is_deleted = false AND published_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_group_id_published_at_id ON posts(group_id, published_at DESC, id DESC)
+    WHERE published_at IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Anonymous replies no longer record their author. They keep an owner tag
+-- instead (a salted HMAC of the author's ID under ANONYMOUS_OWNER_KEY) that
+-- lets the author edit and delete them. Anonymous replies saved with an
+-- author before have it removed; they can no longer be edited or deleted by
+-- their author.
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS owner_tag TEXT;
+
+UPDATE replies SET author_id = NULL WHERE is_anonymous = true AND author_id IS NOT NULL;
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+
+	"posting-app/domain"
+)
+
+type AccountUnlockRepository struct {
+	db *sql.DB
+}
+
+func NewAccountUnlockRepository(db *sql.DB) *AccountUnlockRepository {
+	return &AccountUnlockRepository{db: db}
+}
+
+func (r *AccountUnlockRepository) Create(unlock *domain.AccountUnlock) error {
+	query := `
+		INSERT INTO account_unlocks (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		unlock.UserID,
+		unlock.TokenHash,
+		unlock.ExpiresAt,
+	).Scan(&unlock.ID, &unlock.CreatedAt)
+
+	return err
+}
+
+func (r *AccountUnlockRepository) GetByTokenHash(tokenHash string) (*domain.AccountUnlock, error) {
+	unlock := &domain.AccountUnlock{}
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM account_unlocks
+		WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP AND used_at IS NULL`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&unlock.ID, &unlock.UserID, &unlock.TokenHash,
+		&unlock.ExpiresAt, &unlock.UsedAt, &unlock.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unlock, nil
+}
+
+func (r *AccountUnlockRepository) MarkAsUsed(id int) error {
+	query := `UPDATE account_unlocks SET used_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *AccountUnlockRepository) DeleteExpired() error {
+	query := `DELETE FROM account_unlocks WHERE expires_at < CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query)
+	return err
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"posting-app/domain"
+)
+
+type BookmarkRepository struct {
+	db       *sql.DB
+	postRepo *PostRepository
+}
+
+func NewBookmarkRepository(db *sql.DB) *BookmarkRepository {
+	return &BookmarkRepository{
+		db:       db,
+		postRepo: NewPostRepository(db),
+	}
+}
+
+// visibleBookmarkConditions limits bookmarks to posts their owner can still
+// see: approved, published, not deleted, by an active author and, for group
+// posts, in a group the owner belongs to. $1 is the approved status.
+var visibleBookmarkConditions = []string{
+	"p.status = $1",
+	"p.published_at IS NOT NULL",
+	"p.is_deleted = false",
+	"u.is_active = true",
+	"(p.group_id IS NULL OR EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = p.group_id AND gm.user_id = b.user_id))",
+}
+
+// Save bookmarks a post or, if it is already bookmarked, moves it to
+// collectionID
+func (r *BookmarkRepository) Save(bookmark *domain.Bookmark) error {
+	query := `
+		INSERT INTO bookmarks (user_id, post_id, collection_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, post_id) DO UPDATE SET collection_id = EXCLUDED.collection_id
+		RETURNING id, created_at`
+
+	return r.db.QueryRow(query, bookmark.UserID, bookmark.PostID, bookmark.CollectionID).Scan(&bookmark.ID, &bookmark.CreatedAt)
+}
+
+func (r *BookmarkRepository) Delete(userID, postID int) error {
+	_, err := r.db.Exec("DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2", userID, postID)
+	return err
+}
+
+// GetByUserID lists the bookmarks of a user whose posts are still visible to
+// them, newest first, optionally only those in one collection. It pages like
+// PostRepository.listPosts but by the time the bookmark was saved.
+func (r *BookmarkRepository) GetByUserID(userID int, collectionID *int, pageReq domain.PostPageRequest) (*domain.BookmarkPage, error) {
+	page := &domain.BookmarkPage{}
+	args := []interface{}{domain.PostStatusApproved, userID}
+	conditions := append([]string{"b.user_id = $2"}, visibleBookmarkConditions...)
+	if collectionID != nil {
+		args = append(args, *collectionID)
+		conditions = append(conditions, fmt.Sprintf("b.collection_id = $%d", len(args)))
+	}
+	whereClause := strings.Join(conditions, " AND ")
+	from := `
+		FROM bookmarks b
+		JOIN posts p ON b.post_id = p.id
+		JOIN users u ON p.author_id = u.id
+		LEFT JOIN bookmark_collections bc ON b.collection_id = bc.id`
+
+	if pageReq.WithTotal {
+		var total int
+		err := r.db.QueryRow("SELECT COUNT(*)"+from+" WHERE "+whereClause, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	offset := 0
+	if pageReq.After != nil {
+		args = append(args, pageReq.After.CreatedAt, pageReq.After.ID)
+		whereClause += fmt.Sprintf(" AND (b.created_at, b.id) < ($%d, $%d)", len(args)-1, len(args))
+	} else {
+		offset = (pageReq.Page - 1) * pageReq.Limit
+	}
+	args = append(args, pageReq.Limit+1, offset)
+
+	query := fmt.Sprintf(`
+		SELECT p.id, p.title, p.content, p.content_format, p.content_html, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.publish_at, p.published_at, p.created_at, p.updated_at,
+			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at,
+			   b.id, b.collection_id, bc.name, b.created_at`+from+`
+		WHERE %s
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT $%d OFFSET $%d`, whereClause, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*domain.Post
+	for rows.Next() {
+		bookmark := &domain.Bookmark{}
+		post, err := scanListedPost(rows, &bookmark.ID, &bookmark.CollectionID, &bookmark.CollectionName, &bookmark.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		bookmark.PostID = post.ID
+		bookmark.Post = post
+		page.Bookmarks = append(page.Bookmarks, bookmark)
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Bookmarks) > pageReq.Limit {
+		page.Bookmarks = page.Bookmarks[:pageReq.Limit]
+		posts = posts[:pageReq.Limit]
+		last := page.Bookmarks[len(page.Bookmarks)-1]
+		page.NextCursor = &domain.PostCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	if err := r.postRepo.loadListDetails(posts, &userID); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// GetOrCreateCollection returns the user's collection with the given name,
+// creating it if needed
+func (r *BookmarkRepository) GetOrCreateCollection(userID int, name string) (*domain.BookmarkCollection, error) {
+	collection := &domain.BookmarkCollection{}
+	query := `
+		INSERT INTO bookmark_collections (user_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id, name, created_at`
+
+	err := r.db.QueryRow(query, userID, name).Scan(&collection.ID, &collection.Name, &collection.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (r *BookmarkRepository) GetCollectionByID(userID, collectionID int) (*domain.BookmarkCollection, error) {
+	collection := &domain.BookmarkCollection{}
+	query := `SELECT id, name, created_at FROM bookmark_collections WHERE id = $1 AND user_id = $2`
+
+	err := r.db.QueryRow(query, collectionID, userID).Scan(&collection.ID, &collection.Name, &collection.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// GetCollections lists a user's collections by name with the number of
+// visible bookmarks in each
+func (r *BookmarkRepository) GetCollections(userID int) ([]domain.BookmarkCollection, error) {
+	query := fmt.Sprintf(`
+		SELECT bc.id, bc.name, bc.created_at, COUNT(u.id)
+		FROM bookmark_collections bc
+		LEFT JOIN bookmarks b ON b.collection_id = bc.id
+		LEFT JOIN posts p ON b.post_id = p.id
+		LEFT JOIN users u ON p.author_id = u.id AND %s
+		WHERE bc.user_id = $2
+		GROUP BY bc.id
+		ORDER BY bc.name`, strings.Join(visibleBookmarkConditions, " AND "))
+
+	rows, err := r.db.Query(query, domain.PostStatusApproved, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []domain.BookmarkCollection
+	for rows.Next() {
+		var collection domain.BookmarkCollection
+		err := rows.Scan(&collection.ID, &collection.Name, &collection.CreatedAt, &collection.BookmarksCount)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	return collections, rows.Err()
+}
+
+// DeleteCollection removes a collection. Its bookmarks are kept without a
+// collection.
+func (r *BookmarkRepository) DeleteCollection(userID, collectionID int) error {
+	_, err := r.db.Exec("DELETE FROM bookmark_collections WHERE id = $1 AND user_id = $2", collectionID, userID)
+	return err
+}
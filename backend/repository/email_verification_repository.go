@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+
+	"posting-app/domain"
+)
+
+type EmailVerificationRepository struct {
+	db *sql.DB
+}
+
+func NewEmailVerificationRepository(db *sql.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+func (r *EmailVerificationRepository) Create(verification *domain.EmailVerification) error {
+	query := `
+		INSERT INTO email_verifications (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		verification.UserID,
+		verification.TokenHash,
+		verification.ExpiresAt,
+	).Scan(&verification.ID, &verification.CreatedAt)
+
+	return err
+}
+
+func (r *EmailVerificationRepository) GetByTokenHash(tokenHash string) (*domain.EmailVerification, error) {
+	verification := &domain.EmailVerification{}
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM email_verifications
+		WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP AND used_at IS NULL`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&verification.ID, &verification.UserID, &verification.TokenHash,
+		&verification.ExpiresAt, &verification.UsedAt, &verification.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return verification, nil
+}
+
+func (r *EmailVerificationRepository) MarkAsUsed(id int) error {
+	query := `UPDATE email_verifications SET used_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+func (r *EmailVerificationRepository) DeleteExpired() error {
+	query := `DELETE FROM email_verifications WHERE expires_at < CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query)
+	return err
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"posting-app/domain"
+)
+
+// userEventLockSpace is the first key of the advisory locks that serialize
+// event inserts per recipient. Streams resume from the highest id they have
+// sent, so a user's events must commit in id order: each insert takes the
+// recipient's lock before its id is allocated and holds it until commit.
+const userEventLockSpace = 23
+
+type EventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+func (r *EventRepository) Create(userID int, eventType domain.EventType, data domain.EventData) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1, $2)", userEventLockSpace, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO user_events (user_id, type, data) VALUES ($1, $2, $3)", userID, eventType, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateForGroupMembers writes an event for every member of a group except
+// excludeUserID
+func (r *EventRepository) CreateForGroupMembers(groupID, excludeUserID int, eventType domain.EventType, data domain.EventData) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT user_id FROM group_members WHERE group_id = $1 AND user_id <> $2", groupID, excludeUserID)
+	if err != nil {
+		return err
+	}
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	// Lock the recipients in ascending order, the order unnest returns
+	// them in, so that two group events can never wait on each other
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	if _, err := tx.Exec(`
+		SELECT pg_advisory_xact_lock($1, u.user_id)
+		FROM unnest($2::int[]) AS u(user_id)`, userEventLockSpace, pq.Array(userIDs)); err != nil {
+		return err
+	}
+
+	// Members who joined after the locks were taken are left out rather
+	// than written without their lock
+	if _, err := tx.Exec(`
+		INSERT INTO user_events (user_id, type, data)
+		SELECT gm.user_id, $3, $4
+		FROM group_members gm
+		WHERE gm.group_id = $1 AND gm.user_id = ANY($2)`, groupID, pq.Array(userIDs), eventType, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAfter returns up to limit of a user's events after afterID, oldest
+// first
+func (r *EventRepository) GetAfter(userID int, afterID int64, limit int) ([]domain.Event, error) {
+	query := `
+		SELECT id, user_id, type, data, created_at
+		FROM user_events
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`
+
+	rows, err := r.db.Query(query, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var event domain.Event
+		var data []byte
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Type, &data, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Data = data
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetLatestID returns the ID of a user's newest event, or 0 if they have
+// none
+func (r *EventRepository) GetLatestID(userID int) (int64, error) {
+	var id int64
+	err := r.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM user_events WHERE user_id = $1", userID).Scan(&id)
+	return id, err
+}
+
+// DeleteOlderThan removes events created before the given time and returns
+// how many there were
+func (r *EventRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM user_events WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
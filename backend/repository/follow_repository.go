@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"posting-app/domain"
+)
+
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+// FollowUser does nothing if the follow already exists
+func (r *FollowRepository) FollowUser(followerID, followeeID int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO user_follows (follower_id, followee_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, followerID, followeeID)
+	return err
+}
+
+func (r *FollowRepository) UnfollowUser(followerID, followeeID int) error {
+	_, err := r.db.Exec("DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2", followerID, followeeID)
+	return err
+}
+
+// FollowCategory does nothing if the follow already exists
+func (r *FollowRepository) FollowCategory(userID, categoryID int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO category_follows (user_id, category_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`, userID, categoryID)
+	return err
+}
+
+func (r *FollowRepository) UnfollowCategory(userID, categoryID int) error {
+	_, err := r.db.Exec("DELETE FROM category_follows WHERE user_id = $1 AND category_id = $2", userID, categoryID)
+	return err
+}
+
+func (r *FollowRepository) GetFollowedCategories(userID int) ([]domain.Category, error) {
+	query := `
+		SELECT c.id, c.name, c.description, c.color, c.created_at, c.updated_at
+		FROM categories c
+		JOIN category_follows cf ON c.id = cf.category_id
+		WHERE cf.user_id = $1
+		ORDER BY c.name`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []domain.Category
+	for rows.Next() {
+		var category domain.Category
+		err := rows.Scan(&category.ID, &category.Name, &category.Description, &category.Color, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// GetFollowers lists the active users following userID
+func (r *FollowRepository) GetFollowers(userID int, pageReq domain.PostPageRequest) (*domain.FollowPage, error) {
+	return r.listFollows("follower_id", "followee_id", userID, pageReq)
+}
+
+// GetFollowing lists the active users userID follows
+func (r *FollowRepository) GetFollowing(userID int, pageReq domain.PostPageRequest) (*domain.FollowPage, error) {
+	return r.listFollows("followee_id", "follower_id", userID, pageReq)
+}
+
+// listFollows lists the users in listedColumn of the follows whose
+// ownerColumn is userID, most recent first. It pages by (created_at, user
+// ID) after a cursor and by offset otherwise.
+func (r *FollowRepository) listFollows(listedColumn, ownerColumn string, userID int, pageReq domain.PostPageRequest) (*domain.FollowPage, error) {
+	page := &domain.FollowPage{}
+	args := []interface{}{userID}
+	whereClause := fmt.Sprintf("f.%s = $1 AND u.is_active = true", ownerColumn)
+	from := fmt.Sprintf(`
+		FROM user_follows f
+		JOIN users u ON f.%s = u.id`, listedColumn)
+
+	if pageReq.WithTotal {
+		var total int
+		err := r.db.QueryRow("SELECT COUNT(*)"+from+" WHERE "+whereClause, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	offset := 0
+	if pageReq.After != nil {
+		args = append(args, pageReq.After.CreatedAt, pageReq.After.ID)
+		whereClause += fmt.Sprintf(" AND (f.created_at, u.id) < ($%d, $%d)", len(args)-1, len(args))
+	} else {
+		offset = (pageReq.Page - 1) * pageReq.Limit
+	}
+	args = append(args, pageReq.Limit+1, offset)
+
+	query := fmt.Sprintf(`
+		SELECT u.id, u.display_name, u.bio, f.created_at`+from+`
+		WHERE %s
+		ORDER BY f.created_at DESC, u.id DESC
+		LIMIT $%d OFFSET $%d`, whereClause, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.FollowUser
+		if err := rows.Scan(&user.ID, &user.DisplayName, &user.Bio, &user.FollowedAt); err != nil {
+			return nil, err
+		}
+		page.Users = append(page.Users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Users) > pageReq.Limit {
+		page.Users = page.Users[:pageReq.Limit]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = &domain.PostCursor{CreatedAt: last.FollowedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
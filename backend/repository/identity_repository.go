@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+
+	"posting-app/domain"
+)
+
+type IdentityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) Create(identity *domain.Identity) error {
+	query := `
+		INSERT INTO identities (user_id, issuer, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		RETURNING id, last_login_at, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		identity.UserID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+	).Scan(&identity.ID, &identity.LastLoginAt, &identity.CreatedAt)
+
+	return err
+}
+
+func (r *IdentityRepository) GetByIssuerAndSubject(issuer, subject string) (*domain.Identity, error) {
+	identity := &domain.Identity{}
+	query := `
+		SELECT id, user_id, issuer, subject, email, last_login_at, created_at
+		FROM identities
+		WHERE issuer = $1 AND subject = $2`
+
+	err := r.db.QueryRow(query, issuer, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject,
+		&identity.Email, &identity.LastLoginAt, &identity.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (r *IdentityRepository) UpdateLastLogin(id int, email *string) error {
+	query := `UPDATE identities SET email = $2, last_login_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.Exec(query, id, email)
+	return err
+}
+
+func (r *IdentityRepository) CreateLoginState(state *domain.OIDCLoginState) error {
+	query := `
+		INSERT INTO oidc_login_states (state_hash, code_verifier, nonce, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`
+
+	err := r.db.QueryRow(
+		query,
+		state.StateHash,
+		state.CodeVerifier,
+		state.Nonce,
+		state.IPAddress,
+		state.ExpiresAt,
+	).Scan(&state.CreatedAt)
+
+	return err
+}
+
+// CountPendingLoginStates returns how many unexpired logins were started from
+// the IP address
+func (r *IdentityRepository) CountPendingLoginStates(ipAddress string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM oidc_login_states WHERE ip_address = $1 AND expires_at > CURRENT_TIMESTAMP`
+	err := r.db.QueryRow(query, ipAddress).Scan(&count)
+	return count, err
+}
+
+// ConsumeLoginState deletes and returns an unexpired login state, so each
+// state can complete at most one login
+func (r *IdentityRepository) ConsumeLoginState(stateHash string) (*domain.OIDCLoginState, error) {
+	state := &domain.OIDCLoginState{}
+	query := `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING state_hash, code_verifier, nonce, expires_at, created_at`
+
+	err := r.db.QueryRow(query, stateHash).Scan(
+		&state.StateHash, &state.CodeVerifier, &state.Nonce, &state.ExpiresAt, &state.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (r *IdentityRepository) DeleteExpiredLoginStates() error {
+	query := `DELETE FROM oidc_login_states WHERE expires_at < CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query)
+	return err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"posting-app/domain"
+)
+
+type LoginThrottleRepository struct {
+	db *sql.DB
+}
+
+func NewLoginThrottleRepository(db *sql.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) Get(scope domain.LoginThrottleScope, key string) (*domain.LoginThrottle, error) {
+	throttle := &domain.LoginThrottle{}
+	query := `
+		SELECT scope, key, failed_attempts, last_failed_at, locked_until
+		FROM login_throttles
+		WHERE scope = $1 AND key = $2`
+
+	err := r.db.QueryRow(query, scope, key).Scan(
+		&throttle.Scope, &throttle.Key, &throttle.FailedAttempts,
+		&throttle.LastFailedAt, &throttle.LockedUntil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return throttle, nil
+}
+
+// RecordFailure increments the failure counter and returns the new count.
+// Failures older than window no longer count and the counter starts over.
+func (r *LoginThrottleRepository) RecordFailure(scope domain.LoginThrottleScope, key string, window time.Duration) (int, error) {
+	query := `
+		INSERT INTO login_throttles (scope, key, failed_attempts, last_failed_at)
+		VALUES ($1, $2, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failed_attempts = CASE
+				WHEN login_throttles.last_failed_at < CURRENT_TIMESTAMP - make_interval(secs => $3) THEN 1
+				ELSE login_throttles.failed_attempts + 1
+			END,
+			last_failed_at = CURRENT_TIMESTAMP
+		RETURNING failed_attempts`
+
+	var failedAttempts int
+	err := r.db.QueryRow(query, scope, key, window.Seconds()).Scan(&failedAttempts)
+	return failedAttempts, err
+}
+
+func (r *LoginThrottleRepository) Lock(scope domain.LoginThrottleScope, key string, until time.Time) error {
+	query := `UPDATE login_throttles SET locked_until = $3 WHERE scope = $1 AND key = $2`
+	_, err := r.db.Exec(query, scope, key, until)
+	return err
+}
+
+func (r *LoginThrottleRepository) Reset(scope domain.LoginThrottleScope, key string) error {
+	query := `DELETE FROM login_throttles WHERE scope = $1 AND key = $2`
+	_, err := r.db.Exec(query, scope, key)
+	return err
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"posting-app/domain"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create saves a notification unless its recipient has turned off its type.
+// It reports whether the notification was saved.
+func (r *NotificationRepository) Create(notification *domain.Notification) (bool, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, actor_id, post_id, reply_id, group_id, emoji)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notification_preferences
+			WHERE user_id = $1 AND type = $2 AND enabled = false
+		)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(query, notification.UserID, notification.Type, notification.ActorID, notification.PostID,
+		notification.ReplyID, notification.GroupID, notification.Emoji).Scan(&notification.ID, &notification.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetByUserID lists a user's notifications newest first, optionally only
+// unread ones. It pages by (created_at, id) after a cursor and by offset
+// otherwise. The title of a post is only included while the user can still
+// see it.
+func (r *NotificationRepository) GetByUserID(userID int, unreadOnly bool, pageReq domain.PostPageRequest) (*domain.NotificationPage, error) {
+	page := &domain.NotificationPage{}
+	args := []interface{}{userID}
+	whereClause := "n.user_id = $1"
+	if unreadOnly {
+		whereClause += " AND n.read_at IS NULL"
+	}
+
+	unreadCount, err := r.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	page.UnreadCount = unreadCount
+
+	if pageReq.WithTotal {
+		var total int
+		err := r.db.QueryRow("SELECT COUNT(*) FROM notifications n WHERE "+whereClause, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	offset := 0
+	if pageReq.After != nil {
+		args = append(args, pageReq.After.CreatedAt, pageReq.After.ID)
+		whereClause += fmt.Sprintf(" AND (n.created_at, n.id) < ($%d, $%d)", len(args)-1, len(args))
+	} else {
+		offset = (pageReq.Page - 1) * pageReq.Limit
+	}
+	args = append(args, pageReq.Limit+1, offset)
+
+	query := fmt.Sprintf(`
+		SELECT n.id, n.user_id, n.type, n.actor_id, a.display_name, n.post_id,
+			   CASE WHEN p.is_deleted = false AND (p.group_id IS NULL OR p.author_id = n.user_id OR EXISTS (
+				   SELECT 1 FROM group_members gm WHERE gm.group_id = p.group_id AND gm.user_id = n.user_id
+			   )) THEN p.title END,
+			   n.reply_id, n.group_id, g.name, n.emoji, n.read_at, n.created_at
+		FROM notifications n
+		LEFT JOIN users a ON n.actor_id = a.id AND a.is_active = true
+		LEFT JOIN posts p ON n.post_id = p.id
+		LEFT JOIN groups g ON n.group_id = g.id
+		WHERE %s
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $%d OFFSET $%d`, whereClause, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notification domain.Notification
+		var actorName sql.NullString
+		err := rows.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.ActorID, &actorName,
+			&notification.PostID, &notification.PostTitle, &notification.ReplyID, &notification.GroupID, &notification.GroupName,
+			&notification.Emoji, &notification.ReadAt, &notification.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if notification.ActorID != nil && actorName.Valid {
+			notification.Actor = &domain.NotificationActor{ID: *notification.ActorID, DisplayName: actorName.String}
+		}
+		page.Notifications = append(page.Notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Notifications) > pageReq.Limit {
+		page.Notifications = page.Notifications[:pageReq.Limit]
+		last := page.Notifications[len(page.Notifications)-1]
+		page.NextCursor = &domain.PostCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+func (r *NotificationRepository) CountUnread(userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL", userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one of a user's notifications as read, keeping the original
+// read time if it was already read. It returns sql.ErrNoRows if the user has
+// no such notification.
+func (r *NotificationRepository) MarkRead(userID, notificationID int) error {
+	result, err := r.db.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2`, notificationID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of a user as read and returns
+// how many there were
+func (r *NotificationRepository) MarkAllRead(userID int) (int64, error) {
+	result, err := r.db.Exec("UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND read_at IS NULL", userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetDisabledTypes returns the notification types a user has turned off
+func (r *NotificationRepository) GetDisabledTypes(userID int) ([]domain.NotificationType, error) {
+	rows, err := r.db.Query("SELECT type FROM notification_preferences WHERE user_id = $1 AND enabled = false", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []domain.NotificationType
+	for rows.Next() {
+		var notificationType domain.NotificationType
+		if err := rows.Scan(&notificationType); err != nil {
+			return nil, err
+		}
+		types = append(types, notificationType)
+	}
+	return types, rows.Err()
+}
+
+func (r *NotificationRepository) SetPreference(userID int, notificationType domain.NotificationType, enabled bool) error {
+	_, err := r.db.Exec(`
+		INSERT INTO notification_preferences (user_id, type, enabled) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = CURRENT_TIMESTAMP`,
+		userID, notificationType, enabled)
+	return err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"posting-app/domain"
+)
+
+type PersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPersonalAccessTokenRepository(db *sql.DB) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{db: db}
+}
+
+func (r *PersonalAccessTokenRepository) Create(token *domain.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		token.UserID,
+		token.Name,
+		token.TokenPrefix,
+		token.TokenHash,
+		pq.Array(scopesToStrings(token.Scopes)),
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+
+	return err
+}
+
+// GetActiveByTokenHash returns a token that is neither revoked nor expired
+func (r *PersonalAccessTokenRepository) GetActiveByTokenHash(tokenHash string) (*domain.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, last_used_at, expires_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+
+	return scanPersonalAccessToken(r.db.QueryRow(query, tokenHash))
+}
+
+func (r *PersonalAccessTokenRepository) GetByUserID(userID int) ([]domain.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, last_used_at, expires_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []domain.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Revoke reports false when the token does not exist, belongs to another
+// user or was already revoked
+func (r *PersonalAccessTokenRepository) Revoke(id, userID int) (bool, error) {
+	query := `
+		UPDATE personal_access_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *PersonalAccessTokenRepository) UpdateLastUsed(id int) error {
+	query := `UPDATE personal_access_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPersonalAccessToken(row rowScanner) (*domain.PersonalAccessToken, error) {
+	token := &domain.PersonalAccessToken{}
+	var scopes []string
+
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.TokenHash,
+		pq.Array(&scopes), &token.LastUsedAt, &token.ExpiresAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = make([]domain.TokenScope, 0, len(scopes))
+	for _, scope := range scopes {
+		token.Scopes = append(token.Scopes, domain.TokenScope(scope))
+	}
+
+	return token, nil
+}
+
+func scopesToStrings(scopes []domain.TokenScope) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	return values
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"posting-app/domain"
+)
+
+// countingConnector opens connections to a fake database that counts the
+// queries it receives. The post listing query returns posts rows, the
+// COUNT(*) query a single count, and every other query no rows.
+type countingConnector struct {
+	posts   int
+	queries int
+}
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &countingConn{connector: c}, nil
+}
+
+func (c *countingConnector) Driver() driver.Driver {
+	return nil
+}
+
+type countingConn struct {
+	connector *countingConnector
+}
+
+func (c *countingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *countingConn) Close() error {
+	return nil
+}
+
+func (c *countingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *countingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.connector.queries++
+
+	if strings.HasPrefix(strings.TrimSpace(query), "SELECT COUNT(*)") {
+		return &fakeRows{columns: 1, values: [][]driver.Value{{int64(c.connector.posts)}}}, nil
+	}
+	if !strings.Contains(query, "u.subscription_status") {
+		return &fakeRows{}, nil
+	}
+
+	// A post with its author, followed by the bookmark columns when the
+	// bookmark listing asks for them
+	withBookmark := strings.Contains(query, "b.collection_id")
+	now := time.Now()
+	rows := &fakeRows{columns: 23}
+	if withBookmark {
+		rows.columns += 4
+	}
+	for i := 1; i <= c.connector.posts; i++ {
+		row := []driver.Value{
+			int64(i), "title", "content", "markdown", "<p>content</p>", nil, int64(1), "approved", false, nil, nil, now, now, now,
+			int64(1), "author@example.com", "author", nil, "user", "active", true, now, now,
+		}
+		if withBookmark {
+			row = append(row, int64(i), nil, nil, now)
+		}
+		rows.values = append(rows.values, row)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns int
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return make([]string, r.columns)
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// TestPostListingsUseConstantQueries checks that listing a page of posts
+// runs the same number of queries whether the page holds one post or many,
+// i.e. that the details of a page are loaded in batches rather than per post
+func TestPostListingsUseConstantQueries(t *testing.T) {
+	viewerID := 1
+	pageReq := domain.PostPageRequest{Page: 1, Limit: 50, WithTotal: true}
+
+	listings := map[string]func(db *sql.DB) (int, error){
+		"GetApproved": func(db *sql.DB) (int, error) {
+			page, err := NewPostRepository(db).GetApproved(pageReq, &viewerID)
+			if err != nil {
+				return 0, err
+			}
+			return len(page.Posts), nil
+		},
+		"GetFeed": func(db *sql.DB) (int, error) {
+			page, err := NewPostRepository(db).GetFeed(viewerID, pageReq)
+			if err != nil {
+				return 0, err
+			}
+			return len(page.Posts), nil
+		},
+		"GetByTag": func(db *sql.DB) (int, error) {
+			page, err := NewPostRepository(db).GetByTag("go", pageReq, &viewerID)
+			if err != nil {
+				return 0, err
+			}
+			return len(page.Posts), nil
+		},
+		"BookmarkRepository.GetByUserID": func(db *sql.DB) (int, error) {
+			page, err := NewBookmarkRepository(db).GetByUserID(viewerID, nil, pageReq)
+			if err != nil {
+				return 0, err
+			}
+			return len(page.Bookmarks), nil
+		},
+	}
+
+	for name, list := range listings {
+		t.Run(name, func(t *testing.T) {
+			counts := make(map[int]int)
+			for _, posts := range []int{1, 20} {
+				connector := &countingConnector{posts: posts}
+				db := sql.OpenDB(connector)
+
+				listed, err := list(db)
+				db.Close()
+				if err != nil {
+					t.Fatalf("%d posts: %v", posts, err)
+				}
+				if listed != posts {
+					t.Fatalf("expected %d posts, got %d", posts, listed)
+				}
+				counts[posts] = connector.queries
+			}
+
+			if counts[1] != counts[20] {
+				t.Errorf("a page of 1 post took %d queries, a page of 20 posts took %d", counts[1], counts[20])
+			}
+		})
+	}
+}
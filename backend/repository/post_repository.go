@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"posting-app/domain"
 )
 
@@ -18,18 +21,21 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 
 func (r *PostRepository) Create(post *domain.Post) error {
 	query := `
-		INSERT INTO posts (title, content, thumbnail_url, author_id, status, group_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO posts (title, content, content_format, content_html, thumbnail_url, author_id, status, group_id, publish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at`
 
 	err := r.db.QueryRow(
 		query,
 		post.Title,
 		post.Content,
+		post.ContentFormat,
+		post.ContentHTML,
 		post.ThumbnailURL,
 		post.AuthorID,
 		post.Status,
 		post.GroupID,
+		post.PublishAt,
 	).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
 
 	return err
@@ -38,19 +44,16 @@ func (r *PostRepository) Create(post *domain.Post) error {
 func (r *PostRepository) GetByID(id int) (*domain.Post, error) {
 	post := &domain.Post{}
 	query := `
-		SELECT p.id, p.title, p.content, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.created_at, p.updated_at,
-			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at,
-			   COALESCE(likes_count.count, 0) as likes_count
+		SELECT p.id, p.title, p.content, p.content_format, p.content_html, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.publish_at, p.published_at, p.created_at, p.updated_at,
+			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
-		LEFT JOIN (SELECT post_id, COUNT(*) as count FROM likes GROUP BY post_id) likes_count ON p.id = likes_count.post_id
 		WHERE p.id = $1 AND p.is_deleted = false`
 
 	var author domain.User
 	err := r.db.QueryRow(query, id).Scan(
-		&post.ID, &post.Title, &post.Content, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.CreatedAt, &post.UpdatedAt,
+		&post.ID, &post.Title, &post.Content, &post.ContentFormat, &post.ContentHTML, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.PublishAt, &post.PublishedAt, &post.CreatedAt, &post.UpdatedAt,
 		&author.ID, &author.Email, &author.DisplayName, &author.Bio, &author.Role, &author.SubscriptionStatus, &author.IsActive, &author.CreatedAt, &author.UpdatedAt,
-		&post.LikesCount,
 	)
 
 	if err != nil {
@@ -66,162 +69,267 @@ func (r *PostRepository) GetByID(id int) (*domain.Post, error) {
 	}
 	post.Categories = categories
 
-	// Get replies
-	replies, err := r.getRepliesByPostID(id)
+	// Replies are paged through GetReplies; only their number is loaded here
+	err = r.db.QueryRow("SELECT COUNT(*) FROM replies WHERE post_id = $1 AND is_deleted = false", id).Scan(&post.RepliesCount)
 	if err != nil {
 		return nil, err
 	}
-	post.Replies = replies
+
+	// Get reaction counts; the viewer's own are added by LoadViewerReactions
+	if err := r.loadPostReactions([]*domain.Post{post}, nil); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadPostTags([]*domain.Post{post}); err != nil {
+		return nil, err
+	}
+	if err := r.loadPostMentions([]*domain.Post{post}); err != nil {
+		return nil, err
+	}
 
 	return post, nil
 }
 
-func (r *PostRepository) GetApproved(page, limit int) ([]*domain.Post, int, error) {
-	offset := (page - 1) * limit
+// GetApproved lists public approved posts. IsLiked is set for viewerID when
+// it is not nil.
+func (r *PostRepository) GetApproved(pageReq domain.PostPageRequest, viewerID *int) (*domain.PostPage, error) {
+	conditions := []string{"p.status = $1", "p.published_at IS NOT NULL", "u.is_active = true", "p.is_deleted = false", "p.group_id IS NULL"}
+	return r.listPosts(conditions, []interface{}{domain.PostStatusApproved}, byPublishedAt, pageReq, viewerID)
+}
 
-	// Get total count
-	var total int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM posts p JOIN users u ON p.author_id = u.id WHERE p.status = $1 AND u.is_active = true AND p.is_deleted = false AND p.group_id IS NULL", domain.PostStatusApproved).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+// GetFeed lists published posts by authors the user follows, in categories
+// the user follows, or in the user's groups. Follows only bring in posts
+// outside groups. The follow lists are uncorrelated subqueries, so Postgres
+// hashes them once and filters the newest-first index scan against them
+// however many accounts are followed.
+func (r *PostRepository) GetFeed(userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	conditions := []string{
+		"p.status = $1", "p.published_at IS NOT NULL", "u.is_active = true", "p.is_deleted = false",
+		`((p.group_id IS NULL AND (
+			p.author_id IN (SELECT followee_id FROM user_follows WHERE follower_id = $2)
+			OR EXISTS (
+				SELECT 1 FROM post_categories pc
+				WHERE pc.post_id = p.id
+				AND pc.category_id IN (SELECT category_id FROM category_follows WHERE user_id = $2))))
+		OR p.group_id IN (SELECT group_id FROM group_members WHERE user_id = $2))`,
 	}
+	return r.listPosts(conditions, []interface{}{domain.PostStatusApproved, userID}, byPublishedAt, pageReq, &userID)
+}
 
-	// Get posts
-	query := `
-		SELECT p.id, p.title, p.content, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.created_at, p.updated_at,
-			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at,
-			   COALESCE(likes_count.count, 0) as likes_count
-		FROM posts p
-		JOIN users u ON p.author_id = u.id
-		LEFT JOIN (SELECT post_id, COUNT(*) as count FROM likes GROUP BY post_id) likes_count ON p.id = likes_count.post_id
-		WHERE p.status = $1 AND u.is_active = true AND p.is_deleted = false AND p.group_id IS NULL
-		ORDER BY p.created_at DESC 
-		LIMIT $2 OFFSET $3`
+// GetByTag lists published posts tagged with tag. Group posts are included
+// only for members of the group, so not at all without viewerID.
+func (r *PostRepository) GetByTag(tag string, pageReq domain.PostPageRequest, viewerID *int) (*domain.PostPage, error) {
+	conditions := []string{
+		"p.status = $1", "p.published_at IS NOT NULL", "u.is_active = true", "p.is_deleted = false",
+		"EXISTS (SELECT 1 FROM post_tags pt WHERE pt.post_id = p.id AND pt.tag = $2)",
+	}
+	args := []interface{}{domain.PostStatusApproved, tag}
 
-	rows, err := r.db.Query(query, domain.PostStatusApproved, limit, offset)
-	if err != nil {
-		return nil, 0, err
+	if viewerID != nil {
+		conditions = append(conditions, "(p.group_id IS NULL OR EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = p.group_id AND gm.user_id = $3))")
+		args = append(args, *viewerID)
+	} else {
+		conditions = append(conditions, "p.group_id IS NULL")
 	}
-	defer rows.Close()
 
-	var posts []*domain.Post
-	for rows.Next() {
-		post := &domain.Post{}
-		author := &domain.User{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.CreatedAt, &post.UpdatedAt,
-			&author.ID, &author.Email, &author.DisplayName, &author.Bio, &author.Role, &author.SubscriptionStatus, &author.IsActive, &author.CreatedAt, &author.UpdatedAt,
-			&post.LikesCount,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		post.Author = author
-		
-		// Get categories for each post
-		categories, err := r.GetPostCategories(post.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-		post.Categories = categories
-		
-		posts = append(posts, post)
+	return r.listPosts(conditions, args, byPublishedAt, pageReq, viewerID)
+}
+
+// GetByUserID lists the posts of a user except drafts
+func (r *PostRepository) GetByUserID(userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	conditions := []string{"p.author_id = $1", "p.status <> $2", "p.is_deleted = false"}
+	return r.listPosts(conditions, []interface{}{userID, domain.PostStatusDraft}, byCreatedAt, pageReq, &userID)
+}
+
+func (r *PostRepository) GetDraftsByUserID(userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	conditions := []string{"p.author_id = $1", "p.status = $2", "p.is_deleted = false"}
+	return r.listPosts(conditions, []interface{}{userID, domain.PostStatusDraft}, byCreatedAt, pageReq, &userID)
+}
+
+// GetForAdmin lists posts for moderation. Drafts are private to their
+// authors and never included.
+func (r *PostRepository) GetForAdmin(pageReq domain.PostPageRequest, status *domain.PostStatus) (*domain.PostPage, error) {
+	conditions := []string{"u.is_active = true", "p.status <> $1"}
+	args := []interface{}{domain.PostStatusDraft}
+
+	if status != nil {
+		conditions = append(conditions, "p.status = $2")
+		args = append(args, *status)
 	}
 
-	return posts, total, nil
+	return r.listPosts(conditions, args, byCreatedAt, pageReq, nil)
 }
 
-func (r *PostRepository) GetByUserID(userID, page, limit int) ([]*domain.Post, int, error) {
-	offset := (page - 1) * limit
+// postOrder is the timestamp a listing is sorted and paged by, newest first
+// with the id as tie-breaker
+type postOrder struct {
+	column string
+	key    func(post *domain.Post) time.Time
+}
+
+var (
+	// byPublishedAt orders published posts, so that a scheduled post shows
+	// up at the top when it is published rather than at its creation time
+	byPublishedAt = postOrder{"p.published_at", func(post *domain.Post) time.Time { return *post.PublishedAt }}
+	// byCreatedAt orders listings that include unpublished posts
+	byCreatedAt = postOrder{"p.created_at", func(post *domain.Post) time.Time { return post.CreatedAt }}
+)
+
+// listPosts returns one page of the posts matching conditions, newest first
+// by order. A page after a cursor is found by comparing (order, id), which
+// stays fast on deep pages and skips nothing when new posts arrive; without
+// a cursor it falls back to OFFSET. One extra row is read to tell whether
+// another page follows.
+func (r *PostRepository) listPosts(conditions []string, args []interface{}, order postOrder, pageReq domain.PostPageRequest, viewerID *int) (*domain.PostPage, error) {
+	page := &domain.PostPage{}
+	whereClause := strings.Join(conditions, " AND ")
 
 	// Get total count
-	var total int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM posts WHERE author_id = $1 AND is_deleted = false", userID).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+	if pageReq.WithTotal {
+		var total int
+		countQuery := fmt.Sprintf("SELECT COUNT(*) FROM posts p JOIN users u ON p.author_id = u.id WHERE %s", whereClause)
+		err := r.db.QueryRow(countQuery, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
 	}
 
+	offset := 0
+	if pageReq.After != nil {
+		args = append(args, pageReq.After.CreatedAt, pageReq.After.ID)
+		whereClause += fmt.Sprintf(" AND (%s, p.id) < ($%d, $%d)", order.column, len(args)-1, len(args))
+	} else {
+		offset = (pageReq.Page - 1) * pageReq.Limit
+	}
+	args = append(args, pageReq.Limit+1, offset)
+
 	// Get posts
-	query := `
-		SELECT p.id, p.title, p.content, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.created_at, p.updated_at,
-			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at,
-			   COALESCE(likes_count.count, 0) as likes_count
+	query := fmt.Sprintf(`
+		SELECT p.id, p.title, p.content, p.content_format, p.content_html, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.publish_at, p.published_at, p.created_at, p.updated_at,
+			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
-		LEFT JOIN (SELECT post_id, COUNT(*) as count FROM likes GROUP BY post_id) likes_count ON p.id = likes_count.post_id
-		WHERE p.author_id = $1 AND p.is_deleted = false
-		ORDER BY p.created_at DESC 
-		LIMIT $2 OFFSET $3`
+		WHERE %s
+		ORDER BY %s DESC, p.id DESC
+		LIMIT $%d OFFSET $%d`, whereClause, order.column, len(args)-1, len(args))
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var posts []*domain.Post
 	for rows.Next() {
-		post := &domain.Post{}
-		author := &domain.User{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.CreatedAt, &post.UpdatedAt,
-			&author.ID, &author.Email, &author.DisplayName, &author.Bio, &author.Role, &author.SubscriptionStatus, &author.IsActive, &author.CreatedAt, &author.UpdatedAt,
-			&post.LikesCount,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		post.Author = author
-		
-		// Get categories for each post
-		categories, err := r.GetPostCategories(post.ID)
+		post, err := scanListedPost(rows)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
-		post.Categories = categories
-		
-		posts = append(posts, post)
+		page.Posts = append(page.Posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Posts) > pageReq.Limit {
+		page.Posts = page.Posts[:pageReq.Limit]
+		last := page.Posts[len(page.Posts)-1]
+		page.NextCursor = &domain.PostCursor{CreatedAt: order.key(last), ID: last.ID}
+	}
+
+	if err := r.loadListDetails(page.Posts, viewerID); err != nil {
+		return nil, err
 	}
 
-	return posts, total, nil
+	return page, nil
 }
 
-func (r *PostRepository) GetForAdmin(page, limit int, status *domain.PostStatus) ([]*domain.Post, int, error) {
+// scanListedPost scans a post row with its author
+func scanListedPost(row rowScanner, extra ...interface{}) (*domain.Post, error) {
+	post := &domain.Post{}
+	author := &domain.User{}
+	dest := []interface{}{
+		&post.ID, &post.Title, &post.Content, &post.ContentFormat, &post.ContentHTML, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.PublishAt, &post.PublishedAt, &post.CreatedAt, &post.UpdatedAt,
+		&author.ID, &author.Email, &author.DisplayName, &author.Bio, &author.Role, &author.SubscriptionStatus, &author.IsActive, &author.CreatedAt, &author.UpdatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	post.Author = author
+	return post, nil
+}
+
+// Search returns approved posts matching every term, ranked by relevance.
+// A term matches through the tsvector index or, for text without word
+// boundaries such as Japanese, as a substring through the trigram indexes.
+// Group posts are only visible to members of the group.
+func (r *PostRepository) Search(params domain.PostSearchParams, userID *int, page, limit int) ([]*domain.PostSearchResult, int, error) {
 	offset := (page - 1) * limit
 
-	whereClause := "u.is_active = true"
-	args := []interface{}{limit, offset}
+	conditions := []string{"p.status = $1", "p.published_at IS NOT NULL", "p.is_deleted = false", "u.is_active = true"}
+	args := []interface{}{domain.PostStatusApproved}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
 
-	if status != nil {
-		whereClause += " AND p.status = $3"
-		args = append(args, *status)
+	if userID != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"(p.group_id IS NULL OR EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = p.group_id AND gm.user_id = %s))",
+			addArg(*userID)))
+	} else {
+		conditions = append(conditions, "p.group_id IS NULL")
+	}
+
+	for _, term := range params.Terms {
+		tsArg := addArg(term)
+		likeArg := addArg("%" + escapeLike(term) + "%")
+		conditions = append(conditions, fmt.Sprintf(
+			"(p.search_vector @@ plainto_tsquery('simple', %s) OR p.title ILIKE %s OR p.content ILIKE %s)",
+			tsArg, likeArg, likeArg))
 	}
 
+	if params.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM post_categories pc WHERE pc.post_id = p.id AND pc.category_id = %s)",
+			addArg(*params.CategoryID)))
+	}
+	if params.AuthorID != nil {
+		conditions = append(conditions, "p.author_id = "+addArg(*params.AuthorID))
+	}
+	if params.GroupID != nil {
+		conditions = append(conditions, "p.group_id = "+addArg(*params.GroupID))
+	}
+	if params.From != nil {
+		conditions = append(conditions, "p.published_at >= "+addArg(*params.From))
+	}
+	if params.Until != nil {
+		conditions = append(conditions, "p.published_at < "+addArg(*params.Until))
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
 	// Get total count
 	var total int
-	countWhereClause := "u.is_active = true"
-	var countArgs []interface{}
-	if status != nil {
-		countWhereClause += " AND p.status = $1"
-		countArgs = []interface{}{*status}
-	}
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM posts p JOIN users u ON p.author_id = u.id WHERE %s", countWhereClause)
-	err := r.db.QueryRow(countQuery, countArgs...).Scan(&total)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM posts p JOIN users u ON p.author_id = u.id WHERE %s", whereClause)
+	err := r.db.QueryRow(countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get posts
+	// Title matches weigh more than content matches
+	queryArg := addArg(strings.Join(params.Terms, " "))
 	query := fmt.Sprintf(`
-		SELECT p.id, p.title, p.content, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.created_at, p.updated_at,
+		SELECT p.id, p.title, p.content, p.content_format, p.content_html, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.publish_at, p.published_at, p.created_at, p.updated_at,
 			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at,
-			   COALESCE(likes_count.count, 0) as likes_count
+			   ts_rank(p.search_vector, plainto_tsquery('simple', %[2]s))
+			   + word_similarity(%[2]s, p.title) + 0.5 * word_similarity(%[2]s, p.content) as rank
 		FROM posts p
 		JOIN users u ON p.author_id = u.id
-		LEFT JOIN (SELECT post_id, COUNT(*) as count FROM likes GROUP BY post_id) likes_count ON p.id = likes_count.post_id
-		WHERE %s
-		ORDER BY p.created_at DESC 
-		LIMIT $1 OFFSET $2`, whereClause)
+		WHERE %[1]s
+		ORDER BY rank DESC, p.published_at DESC, p.id DESC
+		LIMIT %[3]s OFFSET %[4]s`, whereClause, queryArg, addArg(limit), addArg(offset))
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -229,40 +337,42 @@ func (r *PostRepository) GetForAdmin(page, limit int, status *domain.PostStatus)
 	}
 	defer rows.Close()
 
+	var results []*domain.PostSearchResult
 	var posts []*domain.Post
 	for rows.Next() {
-		post := &domain.Post{}
-		author := &domain.User{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.CreatedAt, &post.UpdatedAt,
-			&author.ID, &author.Email, &author.DisplayName, &author.Bio, &author.Role, &author.SubscriptionStatus, &author.IsActive, &author.CreatedAt, &author.UpdatedAt,
-			&post.LikesCount,
-		)
+		result := &domain.PostSearchResult{}
+		post, err := scanListedPost(rows, &result.Rank)
 		if err != nil {
 			return nil, 0, err
 		}
-		post.Author = author
-		
-		// Get categories for each post
-		categories, err := r.GetPostCategories(post.ID)
-		if err != nil {
-			return nil, 0, err
-		}
-		post.Categories = categories
-		
+		result.Post = post
+		results = append(results, result)
 		posts = append(posts, post)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.loadListDetails(posts, userID); err != nil {
+		return nil, 0, err
+	}
 
-	return posts, total, nil
+	return results, total, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 func (r *PostRepository) Update(post *domain.Post) error {
 	query := `
 		UPDATE posts 
-		SET title = $1, content = $2, thumbnail_url = $3, status = $4, group_id = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6`
+		SET title = $1, content = $2, content_format = $3, content_html = $4, thumbnail_url = $5, status = $6, group_id = $7, publish_at = $8,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9`
 
-	_, err := r.db.Exec(query, post.Title, post.Content, post.ThumbnailURL, post.Status, post.GroupID, post.ID)
+	_, err := r.db.Exec(query, post.Title, post.Content, post.ContentFormat, post.ContentHTML, post.ThumbnailURL, post.Status, post.GroupID, post.PublishAt, post.ID)
 	return err
 }
 
@@ -278,77 +388,219 @@ func (r *PostRepository) UpdateStatus(id int, status domain.PostStatus) error {
 	return err
 }
 
+// MarkPublished sets published_at unless the post was published before
+func (r *PostRepository) MarkPublished(id int) error {
+	query := `UPDATE posts SET published_at = COALESCE(published_at, CURRENT_TIMESTAMP) WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// PublishDue publishes approved posts whose publish_at has passed and
+// returns their IDs
+func (r *PostRepository) PublishDue() ([]int, error) {
+	query := `
+		UPDATE posts SET published_at = CURRENT_TIMESTAMP
+		WHERE status = $1 AND published_at IS NULL AND publish_at <= CURRENT_TIMESTAMP AND is_deleted = false
+		RETURNING id`
+
+	rows, err := r.db.Query(query, domain.PostStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 func (r *PostRepository) CreateReply(reply *domain.Reply) error {
 	query := `
-		INSERT INTO replies (content, post_id, author_id, is_anonymous)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO replies (content, content_format, content_html, post_id, parent_reply_id, depth, author_id, owner_tag, is_anonymous)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at`
 
 	err := r.db.QueryRow(
 		query,
 		reply.Content,
+		reply.ContentFormat,
+		reply.ContentHTML,
 		reply.PostID,
+		reply.ParentReplyID,
+		reply.Depth,
 		reply.AuthorID,
+		reply.OwnerTag,
 		reply.IsAnonymous,
 	).Scan(&reply.ID, &reply.CreatedAt)
 
 	return err
 }
 
-func (r *PostRepository) getRepliesByPostID(postID int) ([]domain.Reply, error) {
-	query := `
-		SELECT r.id, r.content, r.post_id, r.author_id, r.is_anonymous, r.created_at,
-			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at
+// replyColumns selects a reply with its author and the number of direct
+// replies to it, not counting deleted ones, for scanReply
+const replyColumns = `
+		SELECT r.id, r.content, r.content_format, r.content_html, r.post_id, r.parent_reply_id, r.depth, r.author_id, r.owner_tag, r.is_anonymous, r.is_deleted, r.edited_at, r.created_at,
+			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at,
+			   (SELECT COUNT(*) FROM replies c WHERE c.parent_reply_id = r.id AND c.is_deleted = false) as replies_count
 		FROM replies r
-		LEFT JOIN users u ON r.author_id = u.id AND u.is_active = true
-		WHERE r.post_id = $1
-		ORDER BY r.created_at ASC`
+		LEFT JOIN users u ON r.author_id = u.id AND u.is_active = true`
 
-	rows, err := r.db.Query(query, postID)
+func (r *PostRepository) GetReplyByID(id int) (*domain.Reply, error) {
+	reply, err := scanReply(r.db.QueryRow(replyColumns+` WHERE r.id = $1`, id))
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var replies []domain.Reply
-	for rows.Next() {
-		reply := domain.Reply{}
-		var author *domain.User
+	return &reply, nil
+}
 
-		var userID, userEmail, userDisplayName, userBio, userRole, userSubscriptionStatus sql.NullString
-		var userIsActive sql.NullBool
-		var userCreatedAt, userUpdatedAt sql.NullTime
+// GetReplies lists the direct replies to a post, or to parentReplyID when it
+// is not nil, oldest first, with their reactions. Like listPosts it pages by
+// (created_at, id) after a cursor and by offset otherwise.
+func (r *PostRepository) GetReplies(postID int, parentReplyID *int, pageReq domain.PostPageRequest, viewerID *int) (*domain.ReplyPage, error) {
+	page := &domain.ReplyPage{}
+	conditions := []string{"r.post_id = $1"}
+	args := []interface{}{postID}
+	if parentReplyID != nil {
+		args = append(args, *parentReplyID)
+		conditions = append(conditions, fmt.Sprintf("r.parent_reply_id = $%d", len(args)))
+	} else {
+		conditions = append(conditions, "r.parent_reply_id IS NULL")
+	}
+	whereClause := strings.Join(conditions, " AND ")
 
-		err := rows.Scan(
-			&reply.ID, &reply.Content, &reply.PostID, &reply.AuthorID, &reply.IsAnonymous, &reply.CreatedAt,
-			&userID, &userEmail, &userDisplayName, &userBio, &userRole, &userSubscriptionStatus, &userIsActive, &userCreatedAt, &userUpdatedAt,
-		)
+	if pageReq.WithTotal {
+		var total int
+		err := r.db.QueryRow("SELECT COUNT(*) FROM replies r WHERE "+whereClause, args...).Scan(&total)
 		if err != nil {
 			return nil, err
 		}
+		page.Total = &total
+	}
 
-		if !reply.IsAnonymous && userID.Valid {
-			id, _ := strconv.Atoi(userID.String)
-			author = &domain.User{
-				ID:                 id,
-				Email:              userEmail.String,
-				DisplayName:        userDisplayName.String,
-				Role:               domain.UserRole(userRole.String),
-				SubscriptionStatus: domain.UserSubscriptionStatus(userSubscriptionStatus.String),
-				IsActive:           userIsActive.Bool,
-				CreatedAt:          userCreatedAt.Time,
-				UpdatedAt:          userUpdatedAt.Time,
-			}
-			if userBio.Valid {
-				author.Bio = &userBio.String
-			}
+	offset := 0
+	if pageReq.After != nil {
+		args = append(args, pageReq.After.CreatedAt, pageReq.After.ID)
+		whereClause += fmt.Sprintf(" AND (r.created_at, r.id) > ($%d, $%d)", len(args)-1, len(args))
+	} else {
+		offset = (pageReq.Page - 1) * pageReq.Limit
+	}
+	args = append(args, pageReq.Limit+1, offset)
+
+	query := fmt.Sprintf(replyColumns+`
+		WHERE %s
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $%d OFFSET $%d`, whereClause, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		reply, err := scanReply(rows)
+		if err != nil {
+			return nil, err
 		}
+		page.Replies = append(page.Replies, reply)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Replies) > pageReq.Limit {
+		page.Replies = page.Replies[:pageReq.Limit]
+		last := page.Replies[len(page.Replies)-1]
+		page.NextCursor = &domain.PostCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	if err := r.loadReplyReactions(page.Replies, viewerID); err != nil {
+		return nil, err
+	}
+	if err := r.loadReplyMentions(page.Replies); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *PostRepository) UpdateReply(reply *domain.Reply) error {
+	query := `
+		UPDATE replies
+		SET content = $1, content_format = $2, content_html = $3, edited_at = NOW()
+		WHERE id = $4 AND is_deleted = false
+		RETURNING edited_at`
+
+	return r.db.QueryRow(query, reply.Content, reply.ContentFormat, reply.ContentHTML, reply.ID).Scan(&reply.EditedAt)
+}
+
+// DeleteReply turns a reply into a tombstone. The row stays so that replies
+// to it keep their parent; its reactions are removed with the content.
+func (r *PostRepository) DeleteReply(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE replies
+		SET is_deleted = true, content = '', content_html = '', deleted_at = NOW()
+		WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM reply_reactions WHERE reply_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-		reply.Author = author
-		replies = append(replies, reply)
+// scanReply scans a row selected with replyColumns. The author is left
+// empty for anonymous and deleted replies.
+func scanReply(row rowScanner) (domain.Reply, error) {
+	reply := domain.Reply{}
+
+	var userID, userEmail, userDisplayName, userBio, userRole, userSubscriptionStatus sql.NullString
+	var userIsActive sql.NullBool
+	var userCreatedAt, userUpdatedAt sql.NullTime
+
+	err := row.Scan(
+		&reply.ID, &reply.Content, &reply.ContentFormat, &reply.ContentHTML, &reply.PostID, &reply.ParentReplyID, &reply.Depth, &reply.AuthorID, &reply.OwnerTag, &reply.IsAnonymous, &reply.IsDeleted, &reply.EditedAt, &reply.CreatedAt,
+		&userID, &userEmail, &userDisplayName, &userBio, &userRole, &userSubscriptionStatus, &userIsActive, &userCreatedAt, &userUpdatedAt,
+		&reply.RepliesCount,
+	)
+	if err != nil {
+		return reply, err
+	}
+
+	if !reply.IsAnonymous && !reply.IsDeleted && userID.Valid {
+		id, _ := strconv.Atoi(userID.String)
+		reply.Author = &domain.User{
+			ID:                 id,
+			Email:              userEmail.String,
+			DisplayName:        userDisplayName.String,
+			Role:               domain.UserRole(userRole.String),
+			SubscriptionStatus: domain.UserSubscriptionStatus(userSubscriptionStatus.String),
+			IsActive:           userIsActive.Bool,
+			CreatedAt:          userCreatedAt.Time,
+			UpdatedAt:          userUpdatedAt.Time,
+		}
+		if userBio.Valid {
+			reply.Author.Bio = &userBio.String
+		}
 	}
 
-	return replies, nil
+	return reply, nil
 }
 
 // Category related methods
@@ -378,6 +630,12 @@ func (r *PostRepository) CreateCategory(category *domain.Category) error {
 	return err
 }
 
+func (r *PostRepository) CategoryExists(id int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
 func (r *PostRepository) AddPostCategories(postID int, categoryIDs []int) error {
 	if len(categoryIDs) == 0 {
 		return nil
@@ -399,6 +657,122 @@ func (r *PostRepository) AddPostCategories(postID int, categoryIDs []int) error
 	return nil
 }
 
+// SetPostCategories replaces the categories of a post. Unlike
+// AddPostCategories an empty list clears them.
+func (r *PostRepository) SetPostCategories(postID int, categoryIDs []int) error {
+	_, err := r.db.Exec("DELETE FROM post_categories WHERE post_id = $1", postID)
+	if err != nil {
+		return err
+	}
+
+	for _, categoryID := range categoryIDs {
+		_, err := r.db.Exec("INSERT INTO post_categories (post_id, category_id) VALUES ($1, $2)", postID, categoryID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadListDetails fills in the categories, tags, reactions, mentions, reply
+// count and, when viewerID is not nil, the viewer's reactions and bookmarks
+// for a page of posts. It runs the same six queries however many posts there
+// are.
+func (r *PostRepository) loadListDetails(posts []*domain.Post, viewerID *int) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, len(posts))
+	postsByID := make(map[int]*domain.Post, len(posts))
+	for i, post := range posts {
+		postIDs[i] = int64(post.ID)
+		postsByID[post.ID] = post
+	}
+
+	// Categories
+	rows, err := r.db.Query(`
+		SELECT pc.post_id, c.id, c.name, c.description, c.color, c.created_at, c.updated_at
+		FROM categories c
+		JOIN post_categories pc ON c.id = pc.category_id
+		WHERE pc.post_id = ANY($1)
+		ORDER BY c.name`, pq.Array(postIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		var category domain.Category
+		err := rows.Scan(&postID, &category.ID, &category.Name, &category.Description, &category.Color, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		post := postsByID[postID]
+		post.Categories = append(post.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := r.loadPostTags(posts); err != nil {
+		return err
+	}
+
+	// Reactions, including the likes count and the viewer's own reactions
+	if err := r.loadPostReactions(posts, viewerID); err != nil {
+		return err
+	}
+
+	if err := r.loadPostMentions(posts); err != nil {
+		return err
+	}
+
+	// Reply counts
+	replyRows, err := r.db.Query(`
+		SELECT post_id, COUNT(*)
+		FROM replies
+		WHERE post_id = ANY($1) AND is_deleted = false
+		GROUP BY post_id`, pq.Array(postIDs))
+	if err != nil {
+		return err
+	}
+	defer replyRows.Close()
+
+	for replyRows.Next() {
+		var postID, count int
+		if err := replyRows.Scan(&postID, &count); err != nil {
+			return err
+		}
+		postsByID[postID].RepliesCount = count
+	}
+	if err := replyRows.Err(); err != nil {
+		return err
+	}
+
+	// Bookmark state
+	if viewerID == nil {
+		return nil
+	}
+	bookmarkRows, err := r.db.Query(`
+		SELECT post_id FROM bookmarks
+		WHERE post_id = ANY($1) AND user_id = $2`, pq.Array(postIDs), *viewerID)
+	if err != nil {
+		return err
+	}
+	defer bookmarkRows.Close()
+
+	for bookmarkRows.Next() {
+		var postID int
+		if err := bookmarkRows.Scan(&postID); err != nil {
+			return err
+		}
+		postsByID[postID].IsBookmarked = true
+	}
+	return bookmarkRows.Err()
+}
+
 func (r *PostRepository) GetPostCategories(postID int) ([]domain.Category, error) {
 	query := `
 		SELECT c.id, c.name, c.description, c.color, c.created_at, c.updated_at
@@ -425,37 +799,445 @@ func (r *PostRepository) GetPostCategories(postID int) ([]domain.Category, error
 	return categories, nil
 }
 
-// Like related methods
-func (r *PostRepository) ToggleLike(postID, userID int) error {
+// Reaction related methods
+
+// ToggleLike reports whether the post is liked afterwards
+func (r *PostRepository) ToggleLike(postID, userID int) (bool, error) {
 	// Check if like exists
 	var exists bool
-	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM likes WHERE post_id = $1 AND user_id = $2)", postID, userID).Scan(&exists)
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM post_reactions WHERE post_id = $1 AND user_id = $2 AND emoji = $3)", postID, userID, domain.ReactionLike).Scan(&exists)
 	if err != nil {
-		return err
+		return false, err
 	}
-	
+
 	if exists {
-		// Remove like
-		_, err = r.db.Exec("DELETE FROM likes WHERE post_id = $1 AND user_id = $2", postID, userID)
-	} else {
-		// Add like
-		_, err = r.db.Exec("INSERT INTO likes (post_id, user_id) VALUES ($1, $2)", postID, userID)
+		return false, r.RemovePostReaction(postID, userID, domain.ReactionLike)
+	}
+	if _, err := r.AddPostReaction(postID, userID, domain.ReactionLike); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddPostReaction does nothing if the user already added the reaction. It
+// reports whether the reaction was added.
+func (r *PostRepository) AddPostReaction(postID, userID int, emoji string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO post_reactions (post_id, user_id, emoji) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING`, postID, userID, emoji)
+	if err != nil {
+		return false, err
 	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+func (r *PostRepository) RemovePostReaction(postID, userID int, emoji string) error {
+	_, err := r.db.Exec("DELETE FROM post_reactions WHERE post_id = $1 AND user_id = $2 AND emoji = $3", postID, userID, emoji)
 	return err
 }
 
-func (r *PostRepository) GetLikesCount(postID int) (int, error) {
-	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM likes WHERE post_id = $1", postID).Scan(&count)
-	return count, err
+// AddReplyReaction does nothing if the user already added the reaction or
+// the reply is deleted. It reports whether the reaction was added. The reply
+// row is locked so that a concurrent DeleteReply either waits for the
+// reaction and removes it, or is seen here.
+func (r *PostRepository) AddReplyReaction(replyID, userID int, emoji string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO reply_reactions (reply_id, user_id, emoji)
+		SELECT $1, $2, $3
+		WHERE EXISTS (SELECT 1 FROM replies WHERE id = $1 AND is_deleted = false FOR SHARE)
+		ON CONFLICT DO NOTHING`, replyID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
 }
 
-func (r *PostRepository) IsLikedByUser(postID, userID int) (bool, error) {
+func (r *PostRepository) RemoveReplyReaction(replyID, userID int, emoji string) error {
+	_, err := r.db.Exec("DELETE FROM reply_reactions WHERE reply_id = $1 AND user_id = $2 AND emoji = $3", replyID, userID, emoji)
+	return err
+}
+
+func (r *PostRepository) IsBookmarkedByUser(postID, userID int) (bool, error) {
 	var exists bool
-	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM likes WHERE post_id = $1 AND user_id = $2)", postID, userID).Scan(&exists)
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM bookmarks WHERE post_id = $1 AND user_id = $2)", postID, userID).Scan(&exists)
 	return exists, err
 }
 
+// LoadViewerReactions sets MyReactions and IsLiked on a post loaded with
+// GetByID
+func (r *PostRepository) LoadViewerReactions(post *domain.Post, viewerID int) error {
+	rows, err := r.db.Query(`
+		SELECT emoji FROM post_reactions
+		WHERE post_id = $1 AND user_id = $2
+		ORDER BY created_at`, post.ID, viewerID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var emoji string
+		if err := rows.Scan(&emoji); err != nil {
+			return err
+		}
+		post.MyReactions = append(post.MyReactions, emoji)
+		if emoji == domain.ReactionLike {
+			post.IsLiked = true
+		}
+	}
+	return rows.Err()
+}
+
+// loadPostReactions sets the reaction counts of posts, in the order each
+// reaction was first added, along with LikesCount and, when viewerID is not
+// nil, MyReactions and IsLiked
+func (r *PostRepository) loadPostReactions(posts []*domain.Post, viewerID *int) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, len(posts))
+	postsByID := make(map[int]*domain.Post, len(posts))
+	for i, post := range posts {
+		postIDs[i] = int64(post.ID)
+		postsByID[post.ID] = post
+	}
+
+	var viewer sql.NullInt64
+	if viewerID != nil {
+		viewer = sql.NullInt64{Int64: int64(*viewerID), Valid: true}
+	}
+	rows, err := r.db.Query(`
+		SELECT post_id, emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+		FROM post_reactions
+		WHERE post_id = ANY($1)
+		GROUP BY post_id, emoji
+		ORDER BY post_id, MIN(created_at)`, pq.Array(postIDs), viewer)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID, count int
+		var emoji string
+		var mine bool
+		if err := rows.Scan(&postID, &emoji, &count, &mine); err != nil {
+			return err
+		}
+		post := postsByID[postID]
+		post.Reactions = append(post.Reactions, domain.ReactionCount{Emoji: emoji, Count: count})
+		if mine {
+			post.MyReactions = append(post.MyReactions, emoji)
+		}
+		if emoji == domain.ReactionLike {
+			post.LikesCount = count
+			post.IsLiked = mine
+		}
+	}
+	return rows.Err()
+}
+
+// loadReplyReactions is loadPostReactions for replies
+func (r *PostRepository) loadReplyReactions(replies []domain.Reply, viewerID *int) error {
+	if len(replies) == 0 {
+		return nil
+	}
+
+	replyIDs := make([]int64, len(replies))
+	repliesByID := make(map[int]*domain.Reply, len(replies))
+	for i := range replies {
+		replyIDs[i] = int64(replies[i].ID)
+		repliesByID[replies[i].ID] = &replies[i]
+	}
+
+	var viewer sql.NullInt64
+	if viewerID != nil {
+		viewer = sql.NullInt64{Int64: int64(*viewerID), Valid: true}
+	}
+	rows, err := r.db.Query(`
+		SELECT reply_id, emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+		FROM reply_reactions
+		WHERE reply_id = ANY($1)
+		GROUP BY reply_id, emoji
+		ORDER BY reply_id, MIN(created_at)`, pq.Array(replyIDs), viewer)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var replyID, count int
+		var emoji string
+		var mine bool
+		if err := rows.Scan(&replyID, &emoji, &count, &mine); err != nil {
+			return err
+		}
+		reply := repliesByID[replyID]
+		reply.Reactions = append(reply.Reactions, domain.ReactionCount{Emoji: emoji, Count: count})
+		if mine {
+			reply.MyReactions = append(reply.MyReactions, emoji)
+		}
+	}
+	return rows.Err()
+}
+
+// Tag related methods
+
+// SetPostTags replaces the tags of a post
+func (r *PostRepository) SetPostTags(postID int, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM post_tags WHERE post_id = $1", postID)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) > 0 {
+		_, err = tx.Exec(`
+			INSERT INTO post_tags (post_id, tag)
+			SELECT $1, tag FROM unnest($2::text[]) AS tag
+			ON CONFLICT DO NOTHING`, postID, pq.Array(tags))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// loadPostTags fills in the tags of the posts in alphabetical order
+func (r *PostRepository) loadPostTags(posts []*domain.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, len(posts))
+	postsByID := make(map[int]*domain.Post, len(posts))
+	for i, post := range posts {
+		postIDs[i] = int64(post.ID)
+		postsByID[post.ID] = post
+	}
+
+	rows, err := r.db.Query(`
+		SELECT post_id, tag FROM post_tags
+		WHERE post_id = ANY($1)
+		ORDER BY post_id, tag`, pq.Array(postIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		var tag string
+		if err := rows.Scan(&postID, &tag); err != nil {
+			return err
+		}
+		post := postsByID[postID]
+		post.Tags = append(post.Tags, tag)
+	}
+	return rows.Err()
+}
+
+// GetTrendingTags returns the tags of public posts published since the
+// given time, used by the most authors first and then by the most posts, so
+// that one author posting repeatedly does not outrank a tag many people use
+func (r *PostRepository) GetTrendingTags(since time.Time, limit int) ([]domain.TrendingTag, error) {
+	query := `
+		SELECT pt.tag, COUNT(*), COUNT(DISTINCT p.author_id)
+		FROM post_tags pt
+		JOIN posts p ON pt.post_id = p.id
+		JOIN users u ON p.author_id = u.id
+		WHERE p.status = $1 AND p.published_at >= $2 AND p.is_deleted = false
+		AND p.group_id IS NULL AND u.is_active = true
+		GROUP BY pt.tag
+		ORDER BY COUNT(DISTINCT p.author_id) DESC, COUNT(*) DESC, pt.tag
+		LIMIT $3`
+
+	rows, err := r.db.Query(query, domain.PostStatusApproved, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []domain.TrendingTag
+	for rows.Next() {
+		var tag domain.TrendingTag
+		if err := rows.Scan(&tag.Tag, &tag.PostsCount, &tag.AuthorsCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// Mention related methods
+
+// mentionCondition selects, with postID or replyID as $1, the mentions in
+// the post's own content or, when replyID is not nil, in that reply
+func mentionCondition(postID int, replyID *int) (string, int) {
+	if replyID != nil {
+		return "reply_id = $1", *replyID
+	}
+	return "post_id = $1 AND reply_id IS NULL", postID
+}
+
+// SetMentions replaces the mentions in a post's own content, or in one of
+// its replies when replyID is not nil. Users notified of an earlier version
+// stay marked as notified.
+func (r *PostRepository) SetMentions(postID int, replyID *int, mentions []domain.Mention) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	condition, targetID := mentionCondition(postID, replyID)
+	rows, err := tx.Query("DELETE FROM mentions WHERE "+condition+" RETURNING user_id, notified_at", targetID)
+	if err != nil {
+		return err
+	}
+	notifiedAt := make(map[int]sql.NullTime)
+	for rows.Next() {
+		var userID int
+		var notified sql.NullTime
+		if err := rows.Scan(&userID, &notified); err != nil {
+			rows.Close()
+			return err
+		}
+		if notified.Valid {
+			notifiedAt[userID] = notified
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, mention := range mentions {
+		_, err := tx.Exec(`
+			INSERT INTO mentions (post_id, reply_id, user_id, start_offset, end_offset, notified_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			postID, replyID, mention.UserID, mention.Start, mention.End, notifiedAt[mention.UserID])
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TakeUnnotifiedMentions marks the mentions in a post's own content, or in
+// a reply when replyID is not nil, as notified and returns the users of
+// those that were not yet
+func (r *PostRepository) TakeUnnotifiedMentions(postID int, replyID *int) ([]int, error) {
+	condition, targetID := mentionCondition(postID, replyID)
+	rows, err := r.db.Query(`
+		UPDATE mentions SET notified_at = CURRENT_TIMESTAMP
+		WHERE `+condition+` AND notified_at IS NULL
+		RETURNING user_id`, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[int]bool)
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, rows.Err()
+}
+
+// loadPostMentions fills in the mentions of active users in the posts' own
+// content
+func (r *PostRepository) loadPostMentions(posts []*domain.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, len(posts))
+	postsByID := make(map[int]*domain.Post, len(posts))
+	for i, post := range posts {
+		postIDs[i] = int64(post.ID)
+		postsByID[post.ID] = post
+	}
+
+	rows, err := r.db.Query(`
+		SELECT m.post_id, m.user_id, u.display_name, m.start_offset, m.end_offset
+		FROM mentions m
+		JOIN users u ON m.user_id = u.id AND u.is_active = true
+		WHERE m.post_id = ANY($1) AND m.reply_id IS NULL
+		ORDER BY m.post_id, m.start_offset`, pq.Array(postIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		var mention domain.Mention
+		if err := rows.Scan(&postID, &mention.UserID, &mention.DisplayName, &mention.Start, &mention.End); err != nil {
+			return err
+		}
+		post := postsByID[postID]
+		post.Mentions = append(post.Mentions, mention)
+	}
+	return rows.Err()
+}
+
+// loadReplyMentions fills in the mentions of active users in the replies
+func (r *PostRepository) loadReplyMentions(replies []domain.Reply) error {
+	if len(replies) == 0 {
+		return nil
+	}
+
+	replyIDs := make([]int64, len(replies))
+	repliesByID := make(map[int]*domain.Reply, len(replies))
+	for i := range replies {
+		replyIDs[i] = int64(replies[i].ID)
+		repliesByID[replies[i].ID] = &replies[i]
+	}
+
+	rows, err := r.db.Query(`
+		SELECT m.reply_id, m.user_id, u.display_name, m.start_offset, m.end_offset
+		FROM mentions m
+		JOIN users u ON m.user_id = u.id AND u.is_active = true
+		WHERE m.reply_id = ANY($1)
+		ORDER BY m.reply_id, m.start_offset`, pq.Array(replyIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var replyID int
+		var mention domain.Mention
+		if err := rows.Scan(&replyID, &mention.UserID, &mention.DisplayName, &mention.Start, &mention.End); err != nil {
+			return err
+		}
+		reply := repliesByID[replyID]
+		reply.Mentions = append(reply.Mentions, mention)
+	}
+	return rows.Err()
+}
+
 // Group related methods
 func (r *PostRepository) CreateGroup(group *domain.Group) error {
 	query := `INSERT INTO groups (name, description, owner_id) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at`
@@ -508,57 +1290,18 @@ func (r *PostRepository) IsGroupMember(groupID, userID int) (bool, error) {
 	return exists, err
 }
 
-func (r *PostRepository) GetGroupPosts(groupID, userID, page, limit int) ([]*domain.Post, int, error) {
+func (r *PostRepository) GetGroupPosts(groupID, userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
 	// Check if user is member of the group
 	isMember, err := r.IsGroupMember(groupID, userID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	if !isMember {
-		return nil, 0, fmt.Errorf("user is not a member of this group")
-	}
-
-	offset := (page - 1) * limit
-
-	// Get total count
-	var total int
-	err = r.db.QueryRow("SELECT COUNT(*) FROM posts WHERE group_id = $1 AND is_deleted = false", groupID).Scan(&total)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// Get posts
-	query := `
-		SELECT p.id, p.title, p.content, p.thumbnail_url, p.author_id, p.status, p.is_deleted, p.group_id, p.created_at, p.updated_at,
-			   u.id, u.email, u.display_name, u.bio, u.role, u.subscription_status, u.is_active, u.created_at, u.updated_at
-		FROM posts p
-		JOIN users u ON p.author_id = u.id
-		WHERE p.group_id = $1 AND p.is_deleted = false
-		ORDER BY p.created_at DESC 
-		LIMIT $2 OFFSET $3`
-
-	rows, err := r.db.Query(query, groupID, limit, offset)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer rows.Close()
-
-	var posts []*domain.Post
-	for rows.Next() {
-		post := &domain.Post{}
-		author := &domain.User{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.ThumbnailURL, &post.AuthorID, &post.Status, &post.IsDeleted, &post.GroupID, &post.CreatedAt, &post.UpdatedAt,
-			&author.ID, &author.Email, &author.DisplayName, &author.Bio, &author.Role, &author.SubscriptionStatus, &author.IsActive, &author.CreatedAt, &author.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		post.Author = author
-		posts = append(posts, post)
+		return nil, fmt.Errorf("user is not a member of this group")
 	}
 
-	return posts, total, nil
+	conditions := []string{"p.group_id = $1", "p.status = $2", "p.published_at IS NOT NULL", "p.is_deleted = false"}
+	return r.listPosts(conditions, []interface{}{groupID, domain.PostStatusApproved}, byPublishedAt, pageReq, &userID)
 }
 
 func (r *PostRepository) GetUserGroupCount(userID int) (int, error) {
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"posting-app/domain"
+)
+
+type PostRevisionRepository struct {
+	db *sql.DB
+}
+
+func NewPostRevisionRepository(db *sql.DB) *PostRevisionRepository {
+	return &PostRevisionRepository{db: db}
+}
+
+// Create stores revision as the next revision of its post
+func (r *PostRevisionRepository) Create(revision *domain.PostRevision) error {
+	query := `
+		INSERT INTO post_revisions (post_id, revision, title, content, content_format, thumbnail_url, category_ids, editor_id, restored_from)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6, $7, $8
+		FROM post_revisions
+		WHERE post_id = $1
+		RETURNING id, revision, created_at`
+
+	categoryIDs := make([]int64, len(revision.CategoryIDs))
+	for i, id := range revision.CategoryIDs {
+		categoryIDs[i] = int64(id)
+	}
+
+	err := r.db.QueryRow(
+		query,
+		revision.PostID,
+		revision.Title,
+		revision.Content,
+		revision.ContentFormat,
+		revision.ThumbnailURL,
+		pq.Array(categoryIDs),
+		revision.EditorID,
+		revision.RestoredFrom,
+	).Scan(&revision.ID, &revision.Revision, &revision.CreatedAt)
+
+	return err
+}
+
+func (r *PostRevisionRepository) GetByPostID(postID int) ([]domain.PostRevision, error) {
+	query := `
+		SELECT pr.id, pr.post_id, pr.revision, pr.title, pr.content, pr.content_format, pr.thumbnail_url, pr.category_ids,
+			   pr.editor_id, u.display_name, pr.restored_from, pr.approved_at, pr.created_at
+		FROM post_revisions pr
+		LEFT JOIN users u ON pr.editor_id = u.id
+		WHERE pr.post_id = $1
+		ORDER BY pr.revision DESC`
+
+	rows, err := r.db.Query(query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []domain.PostRevision
+	for rows.Next() {
+		revision, err := scanPostRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *PostRevisionRepository) GetByRevision(postID, revision int) (*domain.PostRevision, error) {
+	query := `
+		SELECT pr.id, pr.post_id, pr.revision, pr.title, pr.content, pr.content_format, pr.thumbnail_url, pr.category_ids,
+			   pr.editor_id, u.display_name, pr.restored_from, pr.approved_at, pr.created_at
+		FROM post_revisions pr
+		LEFT JOIN users u ON pr.editor_id = u.id
+		WHERE pr.post_id = $1 AND pr.revision = $2`
+
+	return scanPostRevision(r.db.QueryRow(query, postID, revision))
+}
+
+// MarkLatestApproved records that the post's current revision was approved
+func (r *PostRevisionRepository) MarkLatestApproved(postID int) error {
+	query := `
+		UPDATE post_revisions SET approved_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT id FROM post_revisions WHERE post_id = $1 ORDER BY revision DESC LIMIT 1)`
+
+	_, err := r.db.Exec(query, postID)
+	return err
+}
+
+func scanPostRevision(row rowScanner) (*domain.PostRevision, error) {
+	revision := &domain.PostRevision{}
+	var categoryIDs []int64
+	var editorName sql.NullString
+
+	err := row.Scan(
+		&revision.ID, &revision.PostID, &revision.Revision, &revision.Title, &revision.Content, &revision.ContentFormat, &revision.ThumbnailURL, pq.Array(&categoryIDs),
+		&revision.EditorID, &editorName, &revision.RestoredFrom, &revision.ApprovedAt, &revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.CategoryIDs = make([]int, len(categoryIDs))
+	for i, id := range categoryIDs {
+		revision.CategoryIDs[i] = int(id)
+	}
+	if editorName.Valid {
+		revision.EditorName = &editorName.String
+	}
+	return revision, nil
+}
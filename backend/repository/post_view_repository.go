@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"posting-app/domain"
+)
+
+type PostViewRepository struct {
+	db *sql.DB
+}
+
+func NewPostViewRepository(db *sql.DB) *PostViewRepository {
+	return &PostViewRepository{db: db}
+}
+
+// RecordViews saves a batch of views in one statement. Views already
+// counted for the user and day, and views of posts or by users that no
+// longer exist, are skipped.
+func (r *PostViewRepository) RecordViews(views []domain.PostView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, len(views))
+	userIDs := make([]int64, len(views))
+	days := make([]string, len(views))
+	for i, view := range views {
+		postIDs[i] = int64(view.PostID)
+		userIDs[i] = int64(view.UserID)
+		days[i] = view.ViewedOn.Format(time.DateOnly)
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO post_views (post_id, user_id, viewed_on)
+		SELECT v.post_id, v.user_id, v.viewed_on
+		FROM unnest($1::int[], $2::int[], $3::date[]) AS v(post_id, user_id, viewed_on)
+		WHERE EXISTS (SELECT 1 FROM posts p WHERE p.id = v.post_id)
+		AND EXISTS (SELECT 1 FROM users u WHERE u.id = v.user_id)
+		ON CONFLICT DO NOTHING`, pq.Array(postIDs), pq.Array(userIDs), pq.Array(days))
+	return err
+}
+
+// authorPostsScope selects the posts of author $1 that are neither drafts
+// ($2) nor deleted
+const authorPostsScope = "p.author_id = $1 AND p.status <> $2 AND p.is_deleted = false"
+
+// GetPostStats returns the statistics of a post with a daily series from
+// since, a UTC midnight, to today
+func (r *PostViewRepository) GetPostStats(postID int, since time.Time) (*domain.PostStats, error) {
+	stats := &domain.PostStats{PostID: postID}
+	err := r.db.QueryRow("SELECT title FROM posts WHERE id = $1", postID).Scan(&stats.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{postID}
+	if _, err := r.loadTotals("p.id = $1", args, &stats.PostStatsTotals); err != nil {
+		return nil, err
+	}
+	stats.Daily, err = r.getDaily("p.id = $1", args, since)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetAuthorStats sums the statistics of the author's posts, excluding drafts
+// and deleted posts, with a daily series from since and the topLimit posts
+// viewed most since then
+func (r *PostViewRepository) GetAuthorStats(authorID int, since time.Time, topLimit int) (*domain.PostStatsSummary, error) {
+	summary := &domain.PostStatsSummary{}
+	args := []interface{}{authorID, domain.PostStatusDraft}
+
+	var err error
+	summary.PostsCount, err = r.loadTotals(authorPostsScope, args, &summary.PostStatsTotals)
+	if err != nil {
+		return nil, err
+	}
+	summary.Daily, err = r.getDaily(authorPostsScope, args, since)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.id, p.title, COUNT(*)
+		FROM posts p
+		JOIN post_views v ON v.post_id = p.id
+		WHERE %s AND v.viewed_on >= $%d::date
+		GROUP BY p.id
+		ORDER BY COUNT(*) DESC, p.id DESC
+		LIMIT $%d`, authorPostsScope, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(query, append(args, since.Format(time.DateOnly), topLimit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary.TopPosts = []domain.PostViewCount{}
+	for rows.Next() {
+		var post domain.PostViewCount
+		if err := rows.Scan(&post.PostID, &post.Title, &post.Views); err != nil {
+			return nil, err
+		}
+		summary.TopPosts = append(summary.TopPosts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// loadTotals fills in the all-time totals of the posts matching scope and
+// returns how many posts that is. Likes are the like reaction and replies
+// exclude deleted ones.
+func (r *PostViewRepository) loadTotals(scope string, args []interface{}, totals *domain.PostStatsTotals) (int, error) {
+	query := fmt.Sprintf(`
+		WITH scoped AS (SELECT p.id FROM posts p WHERE %s)
+		SELECT
+			(SELECT COUNT(*) FROM scoped),
+			(SELECT COUNT(*) FROM post_views v WHERE v.post_id IN (SELECT id FROM scoped)),
+			(SELECT COUNT(DISTINCT v.user_id) FROM post_views v WHERE v.post_id IN (SELECT id FROM scoped)),
+			(SELECT COUNT(*) FROM post_reactions pr WHERE pr.post_id IN (SELECT id FROM scoped) AND pr.emoji = $%d),
+			(SELECT COUNT(*) FROM replies rp WHERE rp.post_id IN (SELECT id FROM scoped) AND rp.is_deleted = false)`,
+		scope, len(args)+1)
+
+	var postsCount int
+	err := r.db.QueryRow(query, append(args, domain.ReactionLike)...).Scan(
+		&postsCount, &totals.Views, &totals.UniqueViewers, &totals.Likes, &totals.Replies)
+	return postsCount, err
+}
+
+// getDaily returns the views, likes and replies of the posts matching scope
+// for every UTC day from since to today, including days without any
+func (r *PostViewRepository) getDaily(scope string, args []interface{}, since time.Time) ([]domain.DailyPostStats, error) {
+	n := len(args)
+	query := fmt.Sprintf(`
+		WITH scoped AS (SELECT p.id FROM posts p WHERE %[1]s)
+		SELECT 'view', v.viewed_on, COUNT(*)
+		FROM post_views v
+		WHERE v.post_id IN (SELECT id FROM scoped) AND v.viewed_on >= $%[2]d::date
+		GROUP BY v.viewed_on
+		UNION ALL
+		SELECT 'like', (pr.created_at AT TIME ZONE 'UTC')::date, COUNT(*)
+		FROM post_reactions pr
+		WHERE pr.post_id IN (SELECT id FROM scoped) AND pr.emoji = $%[4]d AND pr.created_at >= $%[3]d
+		GROUP BY 2
+		UNION ALL
+		SELECT 'reply', (rp.created_at AT TIME ZONE 'UTC')::date, COUNT(*)
+		FROM replies rp
+		WHERE rp.post_id IN (SELECT id FROM scoped) AND rp.is_deleted = false AND rp.created_at >= $%[3]d
+		GROUP BY 2`, scope, n+1, n+2, n+3)
+
+	rows, err := r.db.Query(query, append(args, since.Format(time.DateOnly), since, domain.ReactionLike)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var daily []domain.DailyPostStats
+	byDate := make(map[string]*domain.DailyPostStats)
+	today := time.Now().UTC().Format(time.DateOnly)
+	for day := since; ; day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		daily = append(daily, domain.DailyPostStats{Date: date})
+		if date >= today {
+			break
+		}
+	}
+	for i := range daily {
+		byDate[daily[i].Date] = &daily[i]
+	}
+
+	for rows.Next() {
+		var kind string
+		var day time.Time
+		var count int
+		if err := rows.Scan(&kind, &day, &count); err != nil {
+			return nil, err
+		}
+		stats, ok := byDate[day.Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		switch kind {
+		case "view":
+			stats.Views = count
+		case "like":
+			stats.Likes = count
+		case "reply":
+			stats.Replies = count
+		}
+	}
+	return daily, rows.Err()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"database/sql"
+
+	"posting-app/domain"
+)
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		token.UserID,
+		token.TokenHash,
+		token.FamilyID,
+		token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+
+	return err
+}
+
+func (r *RefreshTokenRepository) GetByTokenHash(tokenHash string) (*domain.RefreshToken, error) {
+	token := &domain.RefreshToken{}
+	query := `
+		SELECT id, user_id, token_hash, family_id, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.FamilyID,
+		&token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarkAsUsed flags the token as rotated. It reports false when the token was
+// already used or revoked, which happens when the same token is presented twice.
+func (r *RefreshTokenRepository) MarkAsUsed(id int) (bool, error) {
+	query := `
+		UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, familyID)
+	return err
+}
+
+// RevokeAllForUser revokes every refresh token of the user except those of
+// exceptFamilyID, which may be empty
+func (r *RefreshTokenRepository) RevokeAllForUser(userID int, exceptFamilyID string) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, userID, exceptFamilyID)
+	return err
+}
+
+func (r *RefreshTokenRepository) DeleteExpired() error {
+	query := `DELETE FROM refresh_tokens WHERE expires_at < CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query)
+	return err
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type RevokedTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRevokedTokenRepository(db *sql.DB) *RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+func (r *RevokedTokenRepository) Create(jti string, userID int, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`
+
+	_, err := r.db.Exec(query, jti, userID, expiresAt)
+	return err
+}
+
+func (r *RevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).Scan(&exists)
+	return exists, err
+}
+
+func (r *RevokedTokenRepository) DeleteExpired() error {
+	query := `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query)
+	return err
+}
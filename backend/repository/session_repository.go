@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"posting-app/domain"
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(session *domain.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING last_seen_at, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+	).Scan(&session.LastSeenAt, &session.CreatedAt)
+
+	return err
+}
+
+func (r *SessionRepository) GetByID(id string) (*domain.Session, error) {
+	session := &domain.Session{}
+	query := `
+		SELECT id, user_id, user_agent, ip_address, last_seen_at, expires_at, revoked_at, created_at
+		FROM sessions
+		WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+		&session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt, &session.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (r *SessionRepository) GetActiveByUserID(userID int) ([]domain.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, last_seen_at, expires_at, revoked_at, created_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_seen_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Session{}
+	for rows.Next() {
+		var session domain.Session
+		err := rows.Scan(
+			&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+			&session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt, &session.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (r *SessionRepository) Touch(id string) error {
+	query := `UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// Extend moves the expiry when the session's refresh token is rotated
+func (r *SessionRepository) Extend(id string, expiresAt time.Time) error {
+	query := `UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP, expires_at = $2 WHERE id = $1`
+	_, err := r.db.Exec(query, id, expiresAt)
+	return err
+}
+
+func (r *SessionRepository) Revoke(id string) error {
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// RevokeAllForUser revokes every session of the user except exceptID, which
+// may be empty
+func (r *SessionRepository) RevokeAllForUser(userID int, exceptID string) error {
+	query := `
+		UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+	_, err := r.db.Exec(query, userID, exceptID)
+	return err
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+
+	"posting-app/domain"
+)
+
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) GetByUserID(userID int) (*domain.TwoFactorCredential, error) {
+	credential := &domain.TwoFactorCredential{}
+	query := `
+		SELECT user_id, secret, enabled, last_used_step, confirmed_at, created_at, updated_at
+		FROM two_factor_credentials
+		WHERE user_id = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(
+		&credential.UserID, &credential.Secret, &credential.Enabled, &credential.LastUsedStep,
+		&credential.ConfirmedAt, &credential.CreatedAt, &credential.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return credential, nil
+}
+
+// SavePending stores a new, not yet confirmed secret, replacing any earlier
+// unconfirmed enrollment
+func (r *TwoFactorRepository) SavePending(credential *domain.TwoFactorCredential) error {
+	query := `
+		INSERT INTO two_factor_credentials (user_id, secret, enabled)
+		VALUES ($1, $2, false)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled = false, last_used_step = 0, confirmed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		RETURNING created_at, updated_at`
+
+	return r.db.QueryRow(query, credential.UserID, credential.Secret).Scan(&credential.CreatedAt, &credential.UpdatedAt)
+}
+
+// UpdateSecret replaces the stored secret if it is still oldSecret, so that
+// a concurrent new enrollment is not overwritten
+func (r *TwoFactorRepository) UpdateSecret(userID int, oldSecret, newSecret string) error {
+	query := `
+		UPDATE two_factor_credentials SET secret = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND secret = $2`
+	_, err := r.db.Exec(query, userID, oldSecret, newSecret)
+	return err
+}
+
+func (r *TwoFactorRepository) Enable(userID int) error {
+	query := `
+		UPDATE two_factor_credentials
+		SET enabled = true, confirmed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
+// Delete removes the credential together with all recovery codes
+func (r *TwoFactorRepository) Delete(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM two_factor_credentials WHERE user_id = $1", userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateLastUsedStep records a consumed TOTP time step. It reports false when
+// the step (or a later one) was already used, i.e. the code is a replay.
+func (r *TwoFactorRepository) UpdateLastUsedStep(userID int, step int64) (bool, error) {
+	query := `
+		UPDATE two_factor_credentials SET last_used_step = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND last_used_step < $2`
+
+	result, err := r.db.Exec(query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err = tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode consumes a recovery code and reports whether it was valid
+func (r *TwoFactorRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	query := `
+		UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	result, err := r.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *TwoFactorRepository) CountRemainingRecoveryCodes(userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL", userID).Scan(&count)
+	return count, err
+}
@@ -2,10 +2,16 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 
+	"github.com/lib/pq"
 	"posting-app/domain"
 )
 
+// ErrDisplayNameTaken is returned by Create when another user already has the
+// display name
+var ErrDisplayNameTaken = errors.New("display name is already taken")
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -32,6 +38,11 @@ func (r *UserRepository) Create(user *domain.User) error {
 		user.EmailVerified,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "users_display_name_unique" {
+		return ErrDisplayNameTaken
+	}
+
 	return err
 }
 
@@ -39,13 +50,13 @@ func (r *UserRepository) GetByID(id int) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
 		SELECT id, email, password_hash, display_name, bio, role, subscription_status, 
-			   stripe_customer_id, is_active, email_verified, created_at, updated_at
+			   stripe_customer_id, is_active, email_verified, token_version, created_at, updated_at
 		FROM users WHERE id = $1 AND is_active = true`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.DisplayName, &user.Bio,
 		&user.Role, &user.SubscriptionStatus, &user.StripeCustomerID, &user.IsActive,
-		&user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
+		&user.EmailVerified, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err != nil {
@@ -59,13 +70,13 @@ func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
 		SELECT id, email, password_hash, display_name, bio, role, subscription_status, 
-			   stripe_customer_id, is_active, email_verified, created_at, updated_at
+			   stripe_customer_id, is_active, email_verified, token_version, created_at, updated_at
 		FROM users WHERE email = $1`
 
 	err := r.db.QueryRow(query, email).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.DisplayName, &user.Bio,
 		&user.Role, &user.SubscriptionStatus, &user.StripeCustomerID, &user.IsActive,
-		&user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
+		&user.EmailVerified, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err != nil {
@@ -96,14 +107,22 @@ func (r *UserRepository) Update(user *domain.User) error {
 	return err
 }
 
+// UpdatePassword also bumps the token version so that tokens issued with the
+// old password stop working immediately.
 func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
-	query := `UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	query := `UPDATE users SET password_hash = $1, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
 	_, err := r.db.Exec(query, passwordHash, userID)
 	return err
 }
 
+func (r *UserRepository) MarkEmailVerified(userID int) error {
+	query := `UPDATE users SET email_verified = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.Exec(query, userID)
+	return err
+}
+
 func (r *UserRepository) Deactivate(userID int) error {
-	query := `UPDATE users SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	query := `UPDATE users SET is_active = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err := r.db.Exec(query, userID)
 	return err
 }
@@ -121,7 +140,7 @@ func (r *UserRepository) GetAll(page, limit int) ([]*domain.User, int, error) {
 	// Get users
 	query := `
 		SELECT id, email, password_hash, display_name, bio, role, subscription_status, 
-			   stripe_customer_id, is_active, email_verified, created_at, updated_at
+			   stripe_customer_id, is_active, email_verified, token_version, created_at, updated_at
 		FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	rows, err := r.db.Query(query, limit, offset)
@@ -136,7 +155,7 @@ func (r *UserRepository) GetAll(page, limit int) ([]*domain.User, int, error) {
 		err := rows.Scan(
 			&user.ID, &user.Email, &user.PasswordHash, &user.DisplayName, &user.Bio,
 			&user.Role, &user.SubscriptionStatus, &user.StripeCustomerID, &user.IsActive,
-			&user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
+			&user.EmailVerified, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -148,22 +167,30 @@ func (r *UserRepository) GetAll(page, limit int) ([]*domain.User, int, error) {
 }
 
 func (r *UserRepository) Ban(userID int) error {
-	query := `UPDATE users SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	query := `UPDATE users SET is_active = false, token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err := r.db.Exec(query, userID)
 	return err
 }
 
+// GetTokenVersion returns the current token version of an active user.
+// Inactive (banned or deactivated) users yield sql.ErrNoRows.
+func (r *UserRepository) GetTokenVersion(userID int) (int, error) {
+	var version int
+	err := r.db.QueryRow("SELECT token_version FROM users WHERE id = $1 AND is_active = true", userID).Scan(&version)
+	return version, err
+}
+
 func (r *UserRepository) GetByDisplayName(displayName string) (*domain.User, error) {
 	user := &domain.User{}
 	query := `
 		SELECT id, email, password_hash, display_name, bio, role, subscription_status, 
-			   stripe_customer_id, is_active, email_verified, created_at, updated_at
+			   stripe_customer_id, is_active, email_verified, token_version, created_at, updated_at
 		FROM users WHERE display_name = $1 AND is_active = true`
 
 	err := r.db.QueryRow(query, displayName).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.DisplayName, &user.Bio,
 		&user.Role, &user.SubscriptionStatus, &user.StripeCustomerID, &user.IsActive,
-		&user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
+		&user.EmailVerified, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err != nil {
@@ -176,7 +203,7 @@ func (r *UserRepository) GetByDisplayName(displayName string) (*domain.User, err
 func (r *UserRepository) SearchByDisplayName(query string) ([]domain.User, error) {
 	sqlQuery := `
 		SELECT id, email, password_hash, display_name, bio, role, subscription_status, 
-			   stripe_customer_id, is_active, email_verified, created_at, updated_at
+			   stripe_customer_id, is_active, email_verified, token_version, created_at, updated_at
 		FROM users 
 		WHERE display_name ILIKE '%' || $1 || '%' AND is_active = true
 		ORDER BY display_name
@@ -194,7 +221,7 @@ func (r *UserRepository) SearchByDisplayName(query string) ([]domain.User, error
 		err := rows.Scan(
 			&user.ID, &user.Email, &user.PasswordHash, &user.DisplayName, &user.Bio,
 			&user.Role, &user.SubscriptionStatus, &user.StripeCustomerID, &user.IsActive,
-			&user.EmailVerified, &user.CreatedAt, &user.UpdatedAt,
+			&user.EmailVerified, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
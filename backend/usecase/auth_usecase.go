@@ -2,10 +2,12 @@ package usecase
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -14,25 +16,109 @@ import (
 	"posting-app/repository"
 )
 
+// ErrEmailNotVerified is returned when an action requires a verified email address
+var ErrEmailNotVerified = errors.New("email verification required")
+
+// ErrTwoFactorEnrollmentRequired is returned by AdminLogin for admins who have
+// not enabled two-factor authentication yet
+var ErrTwoFactorEnrollmentRequired = errors.New("two-factor authentication must be enabled for admin access")
+
 type AuthUsecase struct {
-	userRepo          *repository.UserRepository
-	passwordResetRepo *repository.PasswordResetRepository
-	jwtService        *infrastructure.JWTService
+	userRepo              *repository.UserRepository
+	passwordResetRepo     *repository.PasswordResetRepository
+	emailVerificationRepo *repository.EmailVerificationRepository
+	refreshTokenRepo      *repository.RefreshTokenRepository
+	sessionRepo           *repository.SessionRepository
+	revokedTokenRepo      *repository.RevokedTokenRepository
+	accountUnlockRepo     *repository.AccountUnlockRepository
+	twoFactorUsecase      *TwoFactorUsecase
+	tokenUsecase          *PersonalAccessTokenUsecase
+	loginThrottler        *LoginThrottler
+	jwtService            *infrastructure.JWTService
+	mailer                infrastructure.Mailer
+	baseURL               string
+}
+
+// AuthTokens holds either an access/refresh pair or, when the user has
+// two-factor authentication enabled, only an MFAToken to be exchanged via
+// VerifyTwoFactor.
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	MFAToken     string
+}
+
+// ClientInfo describes the client a login comes from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+	Language  string
+}
+
+// sessionTouchInterval limits how often a session's last-seen time is written
+const sessionTouchInterval = time.Minute
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	User      *domain.User
+	SessionID string
+	MFA       bool
+	// Set only for personal access tokens, which are limited to Scopes
+	PersonalAccessTokenID int
+	Scopes                []domain.TokenScope
+}
+
+func (p *Principal) IsPersonalAccessToken() bool {
+	return p.PersonalAccessTokenID != 0
+}
+
+// HasScope reports whether the principal may act within scope. Session
+// tokens are not scoped.
+func (p *Principal) HasScope(scope domain.TokenScope) bool {
+	if !p.IsPersonalAccessToken() {
+		return true
+	}
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
 }
 
 func NewAuthUsecase(
 	userRepo *repository.UserRepository,
 	passwordResetRepo *repository.PasswordResetRepository,
+	emailVerificationRepo *repository.EmailVerificationRepository,
+	refreshTokenRepo *repository.RefreshTokenRepository,
+	sessionRepo *repository.SessionRepository,
+	revokedTokenRepo *repository.RevokedTokenRepository,
+	accountUnlockRepo *repository.AccountUnlockRepository,
+	twoFactorUsecase *TwoFactorUsecase,
+	tokenUsecase *PersonalAccessTokenUsecase,
+	loginThrottler *LoginThrottler,
 	jwtService *infrastructure.JWTService,
+	mailer infrastructure.Mailer,
+	baseURL string,
 ) *AuthUsecase {
 	return &AuthUsecase{
-		userRepo:          userRepo,
-		passwordResetRepo: passwordResetRepo,
-		jwtService:        jwtService,
+		userRepo:              userRepo,
+		passwordResetRepo:     passwordResetRepo,
+		emailVerificationRepo: emailVerificationRepo,
+		refreshTokenRepo:      refreshTokenRepo,
+		sessionRepo:           sessionRepo,
+		revokedTokenRepo:      revokedTokenRepo,
+		accountUnlockRepo:     accountUnlockRepo,
+		twoFactorUsecase:      twoFactorUsecase,
+		tokenUsecase:          tokenUsecase,
+		loginThrottler:        loginThrottler,
+		jwtService:            jwtService,
+		mailer:                mailer,
+		baseURL:               baseURL,
 	}
 }
 
-func (u *AuthUsecase) Register(email, password, displayName string) (*domain.User, error) {
+func (u *AuthUsecase) Register(email, password, displayName, lang string) (*domain.User, error) {
 	// Check if user already exists
 	_, err := u.userRepo.GetByEmail(email)
 	if err == nil {
@@ -52,7 +138,7 @@ func (u *AuthUsecase) Register(email, password, displayName string) (*domain.Use
 		Role:               domain.UserRoleUser,
 		SubscriptionStatus: domain.UserSubscriptionStatusInactive,
 		IsActive:           true,
-		EmailVerified:      true, // For simplicity, we'll skip email verification
+		EmailVerified:      false,
 	}
 
 	err = u.userRepo.Create(user)
@@ -60,63 +146,569 @@ func (u *AuthUsecase) Register(email, password, displayName string) (*domain.Use
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// The user can request another verification mail, so a delivery failure
+	// must not fail the registration
+	err = u.sendVerification(user, lang)
+	if err != nil {
+		slog.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	slog.Info("User registered successfully", "email", email)
 	return user, nil
 }
 
-func (u *AuthUsecase) Login(email, password string) (*domain.User, string, error) {
+func (u *AuthUsecase) VerifyEmail(token string) error {
+	verification, err := u.emailVerificationRepo.GetByTokenHash(hashToken(token))
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	err = u.userRepo.MarkEmailVerified(verification.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	err = u.emailVerificationRepo.MarkAsUsed(verification.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark verification token as used: %w", err)
+	}
+
+	slog.Info("Email verified successfully", "user_id", verification.UserID)
+	return nil
+}
+
+func (u *AuthUsecase) ResendVerification(email, lang string) error {
 	user, err := u.userRepo.GetByEmail(email)
 	if err != nil {
-		return nil, "", errors.New("invalid credentials")
+		// For security reasons, we don't reveal if the email exists
+		slog.Info("Verification resend requested for non-existent email", "email", email)
+		return nil
+	}
+
+	if !user.IsActive || user.EmailVerified {
+		slog.Info("Verification resend requested for inactive or verified user", "email", email)
+		return nil
+	}
+
+	// As in ForgotPassword, a failure must not tell which addresses exist
+	if err := u.sendVerification(user, lang); err != nil {
+		slog.Error("Failed to resend verification email", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+func (u *AuthUsecase) sendVerification(user *domain.User, lang string) error {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	verification := &domain.EmailVerification{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(24 * time.Hour), // 24 hour expiry
+	}
+
+	err = u.emailVerificationRepo.Create(verification)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification: %w", err)
+	}
+
+	verifyURL := u.baseURL + "/verify-email?token=" + url.QueryEscape(token)
+	err = u.sendMail(infrastructure.MailTemplateEmailVerification, lang, user, verifyURL)
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	slog.Info("Verification email sent", "user_id", user.ID)
+	return nil
+}
+
+func (u *AuthUsecase) Login(email, password string, client ClientInfo) (*domain.User, *AuthTokens, error) {
+	err := u.loginThrottler.Check(email, client.IPAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := u.userRepo.GetByEmail(email)
+	if err != nil {
+		u.recordLoginFailure(email, client, nil)
+		return nil, nil, errors.New("invalid credentials")
 	}
 
 	if !user.IsActive {
-		return nil, "", errors.New("account is deactivated")
+		return nil, nil, errors.New("account is deactivated")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return nil, "", errors.New("invalid credentials")
+		u.recordLoginFailure(email, client, user)
+		return nil, nil, errors.New("invalid credentials")
 	}
 
-	token, err := u.jwtService.GenerateAccessToken(user)
+	enabled, err := u.twoFactorUsecase.IsEnabled(user.ID)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, nil, err
+	}
+	if enabled {
+		tokens, err := u.twoFactorChallenge(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		return user, tokens, nil
+	}
+
+	err = u.loginThrottler.Reset(email)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := u.startSession(user, client, false)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	slog.Info("User logged in successfully", "email", email)
-	return user, token, nil
+	return user, tokens, nil
 }
 
-func (u *AuthUsecase) AdminLogin(email, password string) (*domain.User, string, error) {
+func (u *AuthUsecase) AdminLogin(email, password string, client ClientInfo) (*domain.User, *AuthTokens, error) {
+	err := u.loginThrottler.Check(email, client.IPAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	user, err := u.userRepo.GetByEmail(email)
 	if err != nil {
-		return nil, "", errors.New("invalid credentials")
+		u.recordLoginFailure(email, client, nil)
+		return nil, nil, errors.New("invalid credentials")
 	}
 
 	if !user.IsActive {
-		return nil, "", errors.New("account is deactivated")
+		return nil, nil, errors.New("account is deactivated")
 	}
 
 	if user.Role != domain.UserRoleAdmin {
-		return nil, "", errors.New("admin access required")
+		return nil, nil, errors.New("admin access required")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return nil, "", errors.New("invalid credentials")
+		u.recordLoginFailure(email, client, user)
+		return nil, nil, errors.New("invalid credentials")
+	}
+
+	enabled, err := u.twoFactorUsecase.IsEnabled(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !enabled {
+		return nil, nil, ErrTwoFactorEnrollmentRequired
+	}
+
+	tokens, err := u.twoFactorChallenge(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("Admin password verified, awaiting second factor", "email", email)
+	return user, tokens, nil
+}
+
+func (u *AuthUsecase) twoFactorChallenge(user *domain.User) (*AuthTokens, error) {
+	mfaToken, err := u.jwtService.GenerateMFAToken(user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &AuthTokens{MFAToken: mfaToken}, nil
+}
+
+// VerifyTwoFactor completes a login that was answered with an MFA token by
+// checking a TOTP or recovery code.
+func (u *AuthUsecase) VerifyTwoFactor(mfaToken, code string, client ClientInfo) (*domain.User, *AuthTokens, error) {
+	claims, err := u.jwtService.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return nil, nil, errors.New("invalid or expired two-factor session")
+	}
+
+	user, err := u.userRepo.GetByID(claims.UserID)
+	if err != nil || !user.IsActive || claims.TokenVersion != user.TokenVersion {
+		return nil, nil, errors.New("invalid or expired two-factor session")
+	}
+
+	// Code guesses count against the same limits as password guesses
+	err = u.loginThrottler.Check(user.Email, client.IPAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ok, err := u.twoFactorUsecase.VerifyCode(user.ID, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		slog.Warn("Invalid two-factor code", "user_id", user.ID)
+		u.recordLoginFailure(user.Email, client, user)
+		return nil, nil, errors.New("invalid two-factor code")
+	}
+
+	err = u.loginThrottler.Reset(user.Email)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := u.startSession(user, client, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("User completed two-factor login", "user_id", user.ID)
+	return user, tokens, nil
+}
+
+// recordLoginFailure counts a failed login and mails an unlock link when it
+// locks an existing account. Errors are only logged so that the caller's
+// response is the same whether or not the account exists.
+func (u *AuthUsecase) recordLoginFailure(email string, client ClientInfo, user *domain.User) {
+	accountLocked, err := u.loginThrottler.RecordFailure(email, client.IPAddress)
+	if err != nil {
+		slog.Error("Failed to record login failure", "error", err)
+		return
+	}
+
+	if !accountLocked || user == nil {
+		return
+	}
+
+	err = u.sendUnlock(user, client.Language)
+	if err != nil {
+		slog.Error("Failed to send unlock email", "user_id", user.ID, "error", err)
+	}
+}
+
+func (u *AuthUsecase) sendUnlock(user *domain.User, lang string) error {
+	token, err := generateRandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate unlock token: %w", err)
+	}
+
+	unlock := &domain.AccountUnlock{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(24 * time.Hour), // 24 hour expiry
+	}
+
+	err = u.accountUnlockRepo.Create(unlock)
+	if err != nil {
+		return fmt.Errorf("failed to create account unlock: %w", err)
+	}
+
+	unlockURL := u.baseURL + "/unlock-account?token=" + url.QueryEscape(token)
+	err = u.sendMail(infrastructure.MailTemplateAccountUnlock, lang, user, unlockURL)
+	if err != nil {
+		return fmt.Errorf("failed to send unlock email: %w", err)
+	}
+
+	slog.Info("Unlock email sent", "user_id", user.ID)
+	return nil
+}
+
+// UnlockAccount lifts a login lockout using the token from the unlock email
+func (u *AuthUsecase) UnlockAccount(token string) error {
+	unlock, err := u.accountUnlockRepo.GetByTokenHash(hashToken(token))
+	if err != nil {
+		return errors.New("invalid or expired unlock token")
+	}
+
+	err = u.UnlockUser(unlock.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = u.accountUnlockRepo.MarkAsUsed(unlock.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark unlock token as used: %w", err)
+	}
+
+	return nil
+}
+
+// UnlockUser lifts a login lockout on behalf of an admin
+func (u *AuthUsecase) UnlockUser(userID int) error {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
 	}
 
-	token, err := u.jwtService.GenerateAccessToken(user)
+	err = u.loginThrottler.Reset(user.Email)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+		return err
 	}
 
-	slog.Info("Admin logged in successfully", "email", email)
-	return user, token, nil
+	slog.Info("Account unlocked", "user_id", userID)
+	return nil
 }
 
-func (u *AuthUsecase) ForgotPassword(email string) error {
+// RefreshToken exchanges a refresh token for a new access/refresh pair.
+// The presented token is consumed; presenting it again revokes its family.
+func (u *AuthUsecase) RefreshToken(refreshToken string) (*domain.User, *AuthTokens, error) {
+	claims, err := u.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	storedToken, err := u.refreshTokenRepo.GetByTokenHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	if storedToken.UserID != claims.UserID || storedToken.RevokedAt != nil || time.Now().After(storedToken.ExpiresAt) {
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	rotated := false
+	if storedToken.UsedAt == nil {
+		rotated, err = u.refreshTokenRepo.MarkAsUsed(storedToken.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+	}
+
+	if !rotated {
+		// The token was already exchanged once, so either the client or an
+		// attacker holds a stolen copy. Revoke every token in the family.
+		err = u.revokeSession(storedToken.FamilyID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		slog.Warn("Refresh token reuse detected, token family revoked", "user_id", storedToken.UserID, "family_id", storedToken.FamilyID)
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	user, err := u.userRepo.GetByID(storedToken.UserID)
+	if err != nil {
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	// Password changes and bans bump the token version
+	if claims.TokenVersion != user.TokenVersion {
+		err = u.revokeSession(storedToken.FamilyID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	tokens, err := u.issueTokens(user, storedToken.FamilyID, claims.MFA)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = u.sessionRepo.Extend(storedToken.FamilyID, time.Now().Add(u.jwtService.RefreshTokenDuration()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to extend session: %w", err)
+	}
+
+	slog.Info("Refresh token rotated successfully", "user_id", user.ID)
+	return user, tokens, nil
+}
+
+// startSession records a new login and issues its first token pair. The
+// session id doubles as the refresh token family.
+func (u *AuthUsecase) startSession(user *domain.User, client ClientInfo, mfa bool) (*AuthTokens, error) {
+	sessionID, err := generateRandomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	session := &domain.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(u.jwtService.RefreshTokenDuration()),
+	}
+	if client.UserAgent != "" {
+		session.UserAgent = &client.UserAgent
+	}
+	if client.IPAddress != "" {
+		session.IPAddress = &client.IPAddress
+	}
+
+	err = u.sessionRepo.Create(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return u.issueTokens(user, sessionID, mfa)
+}
+
+// issueTokens generates an access/refresh pair in the given token family.
+// mfa records whether the second factor was verified and is carried over on
+// rotation.
+func (u *AuthUsecase) issueTokens(user *domain.User, familyID string, mfa bool) (*AuthTokens, error) {
+	accessToken, err := u.jwtService.GenerateAccessToken(user, familyID, mfa)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	refreshToken, err := u.jwtService.GenerateRefreshToken(user, mfa)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	storedToken := &domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(u.jwtService.RefreshTokenDuration()),
+	}
+
+	err = u.refreshTokenRepo.Create(storedToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Authenticate validates an access token and checks that it has not been
+// revoked by logout, ban, deactivation or a password change. Personal access
+// tokens are accepted as well.
+func (u *AuthUsecase) Authenticate(tokenString string) (*Principal, error) {
+	if isPersonalAccessToken(tokenString) {
+		return u.tokenUsecase.Authenticate(tokenString)
+	}
+
+	claims, err := u.jwtService.ValidateAccessToken(tokenString)
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	tokenVersion, err := u.userRepo.GetTokenVersion(claims.UserID)
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.TokenVersion != tokenVersion {
+		return nil, errors.New("token has been revoked")
+	}
+
+	if claims.ID != "" {
+		revoked, err := u.revokedTokenRepo.IsRevoked(claims.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+
+	session, err := u.sessionRepo.GetByID(claims.SessionID)
+	if err != nil || session.UserID != claims.UserID || session.RevokedAt != nil {
+		return nil, errors.New("session has been revoked")
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		err = u.sessionRepo.Touch(session.ID)
+		if err != nil {
+			slog.Error("Failed to update session last seen time", "session_id", session.ID, "error", err)
+		}
+	}
+
+	return &Principal{
+		User: &domain.User{
+			ID:    claims.UserID,
+			Email: claims.Email,
+			Role:  domain.UserRole(claims.Role),
+		},
+		SessionID: claims.SessionID,
+		MFA:       claims.MFA,
+	}, nil
+}
+
+// Logout revokes the presented access token and the refresh token family it
+// was issued from.
+func (u *AuthUsecase) Logout(tokenString string) error {
+	claims, err := u.jwtService.ValidateAccessToken(tokenString)
+	if err != nil {
+		return errors.New("invalid token")
+	}
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		err = u.revokedTokenRepo.Create(claims.ID, claims.UserID, claims.ExpiresAt.Time)
+		if err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+	}
+
+	if claims.SessionID != "" {
+		err = u.revokeSession(claims.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("User logged out successfully", "user_id", claims.UserID)
+	return nil
+}
+
+// GetSessions lists the user's active sessions
+func (u *AuthUsecase) GetSessions(userID int) ([]domain.Session, error) {
+	return u.sessionRepo.GetActiveByUserID(userID)
+}
+
+// RevokeSession logs out one of the user's sessions
+func (u *AuthUsecase) RevokeSession(userID int, sessionID string) error {
+	session, err := u.sessionRepo.GetByID(sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return errors.New("session not found")
+	}
+
+	err = u.revokeSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// RevokeOtherSessions logs out every session of the user except currentID
+func (u *AuthUsecase) RevokeOtherSessions(userID int, currentID string) error {
+	err := u.sessionRepo.RevokeAllForUser(userID, currentID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	err = u.refreshTokenRepo.RevokeAllForUser(userID, currentID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	slog.Info("Other sessions revoked", "user_id", userID)
+	return nil
+}
+
+// revokeSession ends a session together with its refresh token family.
+// Access tokens of the session are rejected by Authenticate from then on.
+func (u *AuthUsecase) revokeSession(sessionID string) error {
+	err := u.sessionRepo.Revoke(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	err = u.refreshTokenRepo.RevokeFamily(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+
+	return nil
+}
+
+func (u *AuthUsecase) ForgotPassword(email, lang string) error {
 	user, err := u.userRepo.GetByEmail(email)
 	if err != nil {
 		// For security reasons, we don't reveal if the email exists
@@ -130,12 +722,10 @@ func (u *AuthUsecase) ForgotPassword(email string) error {
 	}
 
 	// Generate reset token
-	tokenBytes := make([]byte, 32)
-	_, err = rand.Read(tokenBytes)
+	token, err := generateRandomToken(32)
 	if err != nil {
 		return fmt.Errorf("failed to generate reset token: %w", err)
 	}
-	token := hex.EncodeToString(tokenBytes)
 
 	passwordReset := &domain.PasswordReset{
 		UserID:    user.ID,
@@ -148,9 +738,16 @@ func (u *AuthUsecase) ForgotPassword(email string) error {
 		return fmt.Errorf("failed to create password reset: %w", err)
 	}
 
-	// TODO: Send email with reset token
-	slog.Info("Password reset token generated", "email", email, "token", token)
+	// Unknown addresses get no error, so neither may a delivery failure or
+	// the response would tell which addresses have accounts
+	resetURL := u.baseURL + "/reset-password?token=" + url.QueryEscape(token)
+	err = u.sendMail(infrastructure.MailTemplatePasswordReset, lang, user, resetURL)
+	if err != nil {
+		slog.Error("Failed to send password reset email", "user_id", user.ID, "error", err)
+		return nil
+	}
 
+	slog.Info("Password reset email sent", "user_id", user.ID)
 	return nil
 }
 
@@ -178,6 +775,13 @@ func (u *AuthUsecase) ResetPassword(token, newPassword string) error {
 		return fmt.Errorf("failed to mark reset token as used: %w", err)
 	}
 
+	// The new token version already rejects existing tokens; this also
+	// removes the sessions from the user's session list
+	err = u.RevokeOtherSessions(passwordReset.UserID, "")
+	if err != nil {
+		return err
+	}
+
 	slog.Info("Password reset successfully", "user_id", passwordReset.UserID)
 	return nil
 }
@@ -203,6 +807,38 @@ func (u *AuthUsecase) ChangePassword(userID int, currentPassword, newPassword st
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	err = u.RevokeOtherSessions(userID, "")
+	if err != nil {
+		return err
+	}
+
 	slog.Info("Password changed successfully", "user_id", userID)
 	return nil
 }
+
+func (u *AuthUsecase) sendMail(templateName, lang string, user *domain.User, actionURL string) error {
+	mail, err := infrastructure.RenderMail(templateName, lang, user.Email, map[string]string{
+		"DisplayName": user.DisplayName,
+		"URL":         actionURL,
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.mailer.Send(mail)
+}
+
+func generateRandomToken(size int) (string, error) {
+	tokenBytes := make([]byte, size)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+// hashToken returns the SHA-256 hex digest under which opaque tokens are stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
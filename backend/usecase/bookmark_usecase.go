@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+const maxBookmarkCollectionNameLength = 100
+
+type BookmarkUsecase struct {
+	bookmarkRepo *repository.BookmarkRepository
+	postUsecase  *PostUsecase
+}
+
+func NewBookmarkUsecase(bookmarkRepo *repository.BookmarkRepository, postUsecase *PostUsecase) *BookmarkUsecase {
+	return &BookmarkUsecase{
+		bookmarkRepo: bookmarkRepo,
+		postUsecase:  postUsecase,
+	}
+}
+
+// Bookmark saves a post the user can see, in the named collection if
+// collectionName is not empty. Bookmarking a post again moves it to that
+// collection.
+func (u *BookmarkUsecase) Bookmark(userID, postID int, collectionName string) (*domain.Bookmark, error) {
+	if _, err := u.postUsecase.getVisiblePost(postID, &userID); err != nil {
+		return nil, err
+	}
+
+	bookmark := &domain.Bookmark{
+		UserID: userID,
+		PostID: postID,
+	}
+
+	collectionName = strings.TrimSpace(collectionName)
+	if collectionName != "" {
+		if utf8.RuneCountInString(collectionName) > maxBookmarkCollectionNameLength {
+			return nil, fmt.Errorf("collection name must be at most %d characters", maxBookmarkCollectionNameLength)
+		}
+		collection, err := u.bookmarkRepo.GetOrCreateCollection(userID, collectionName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get collection: %w", err)
+		}
+		bookmark.CollectionID = &collection.ID
+		bookmark.CollectionName = &collection.Name
+	}
+
+	err := u.bookmarkRepo.Save(bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save bookmark: %w", err)
+	}
+
+	slog.Info("Post bookmarked successfully", "post_id", postID, "user_id", userID, "collection_id", bookmark.CollectionID)
+	return bookmark, nil
+}
+
+func (u *BookmarkUsecase) RemoveBookmark(userID, postID int) error {
+	err := u.bookmarkRepo.Delete(userID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to remove bookmark: %w", err)
+	}
+
+	slog.Info("Bookmark removed successfully", "post_id", postID, "user_id", userID)
+	return nil
+}
+
+// GetBookmarks lists bookmarks whose posts the user can still see, all of
+// them or only those in collectionID
+func (u *BookmarkUsecase) GetBookmarks(userID int, collectionID *int, pageReq domain.PostPageRequest) (*domain.BookmarkPage, error) {
+	if collectionID != nil {
+		if _, err := u.bookmarkRepo.GetCollectionByID(userID, *collectionID); err != nil {
+			return nil, errors.New("collection not found")
+		}
+	}
+
+	page, err := u.bookmarkRepo.GetByUserID(userID, collectionID, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+
+	return page, nil
+}
+
+func (u *BookmarkUsecase) GetCollections(userID int) ([]domain.BookmarkCollection, error) {
+	collections, err := u.bookmarkRepo.GetCollections(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// DeleteCollection removes a collection but keeps its bookmarks
+func (u *BookmarkUsecase) DeleteCollection(userID, collectionID int) error {
+	if _, err := u.bookmarkRepo.GetCollectionByID(userID, collectionID); err != nil {
+		return errors.New("collection not found")
+	}
+
+	err := u.bookmarkRepo.DeleteCollection(userID, collectionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	slog.Info("Bookmark collection deleted successfully", "collection_id", collectionID, "user_id", userID)
+	return nil
+}
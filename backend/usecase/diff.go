@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"strings"
+
+	"posting-app/domain"
+)
+
+// diffLines returns a line diff turning a into b, built from their longest
+// common subsequence. Posts are short enough for the quadratic table.
+func diffLines(a, b string) []domain.DiffLine {
+	from := splitLines(a)
+	to := splitLines(b)
+
+	// lcs[i][j] is the LCS length of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]domain.DiffLine, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			diff = append(diff, domain.DiffLine{Op: domain.DiffOpEqual, Text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, domain.DiffLine{Op: domain.DiffOpDelete, Text: from[i]})
+			i++
+		default:
+			diff = append(diff, domain.DiffLine{Op: domain.DiffOpInsert, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		diff = append(diff, domain.DiffLine{Op: domain.DiffOpDelete, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		diff = append(diff, domain.DiffLine{Op: domain.DiffOpInsert, Text: to[j]})
+	}
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"posting-app/domain"
+	"posting-app/infrastructure"
+	"posting-app/repository"
+)
+
+// EventConfig controls the event stream. Events are kept for Retention so
+// that clients can resume after a disconnect. A stream is closed after
+// StreamDuration so that clients reconnect, and are authenticated again,
+// at least that often; Heartbeat is how often an idle stream sends a comment
+// to keep proxies from closing it.
+type EventConfig struct {
+	Retention      time.Duration `envconfig:"EVENT_RETENTION" default:"24h"`
+	StreamDuration time.Duration `envconfig:"EVENT_STREAM_DURATION" default:"15m"`
+	Heartbeat      time.Duration `envconfig:"EVENT_HEARTBEAT" default:"25s"`
+}
+
+// eventBatchSize is how many events are read at a time for one stream
+const eventBatchSize = 100
+
+type EventUsecase struct {
+	eventRepo *repository.EventRepository
+	listener  *infrastructure.EventListener
+	config    EventConfig
+}
+
+// NewEventUsecase creates an EventUsecase. listener may be nil in processes
+// that only publish events, such as the batch jobs; Subscribe must not be
+// called then.
+func NewEventUsecase(eventRepo *repository.EventRepository, listener *infrastructure.EventListener, config EventConfig) *EventUsecase {
+	return &EventUsecase{
+		eventRepo: eventRepo,
+		listener:  listener,
+		config:    config,
+	}
+}
+
+// Publish writes an event for userID. Failures are only logged so that they
+// never fail the action the event is about.
+func (u *EventUsecase) Publish(userID int, eventType domain.EventType, data domain.EventData) {
+	if err := u.eventRepo.Create(userID, eventType, data); err != nil {
+		slog.Error("Failed to publish event", "user_id", userID, "type", eventType, "error", err)
+	}
+}
+
+// PublishToGroup writes an event for every member of a group except
+// excludeUserID. Failures are only logged, as in Publish.
+func (u *EventUsecase) PublishToGroup(groupID, excludeUserID int, eventType domain.EventType, data domain.EventData) {
+	if err := u.eventRepo.CreateForGroupMembers(groupID, excludeUserID, eventType, data); err != nil {
+		slog.Error("Failed to publish group event", "group_id", groupID, "type", eventType, "error", err)
+	}
+}
+
+// Subscribe returns a channel that is signalled when userID may have new
+// events, and a function that ends the subscription
+func (u *EventUsecase) Subscribe(userID int) (<-chan struct{}, func()) {
+	return u.listener.Subscribe(userID)
+}
+
+// GetEventsAfter returns the next events of a user after afterID, oldest
+// first. It returns at most eventBatchSize events; callers read again until
+// none are left.
+func (u *EventUsecase) GetEventsAfter(userID int, afterID int64) ([]domain.Event, error) {
+	events, err := u.eventRepo.GetAfter(userID, afterID, eventBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetLatestEventID is where a new stream without Last-Event-ID starts
+func (u *EventUsecase) GetLatestEventID(userID int) (int64, error) {
+	id, err := u.eventRepo.GetLatestID(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest event: %w", err)
+	}
+
+	return id, nil
+}
+
+// PruneEvents removes events older than the retention period and returns
+// how many were removed
+func (u *EventUsecase) PruneEvents() (int64, error) {
+	count, err := u.eventRepo.DeleteOlderThan(time.Now().Add(-u.config.Retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune events: %w", err)
+	}
+
+	return count, nil
+}
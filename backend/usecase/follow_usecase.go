@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+type FollowUsecase struct {
+	followRepo *repository.FollowRepository
+	userRepo   *repository.UserRepository
+	postRepo   *repository.PostRepository
+}
+
+func NewFollowUsecase(followRepo *repository.FollowRepository, userRepo *repository.UserRepository, postRepo *repository.PostRepository) *FollowUsecase {
+	return &FollowUsecase{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+		postRepo:   postRepo,
+	}
+}
+
+func (u *FollowUsecase) FollowUser(followerID, followeeID int) error {
+	if followerID == followeeID {
+		return errors.New("you cannot follow yourself")
+	}
+
+	if _, err := u.getActiveUser(followeeID); err != nil {
+		return err
+	}
+
+	err := u.followRepo.FollowUser(followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+
+	slog.Info("User followed successfully", "follower_id", followerID, "followee_id", followeeID)
+	return nil
+}
+
+func (u *FollowUsecase) UnfollowUser(followerID, followeeID int) error {
+	err := u.followRepo.UnfollowUser(followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+
+	slog.Info("User unfollowed successfully", "follower_id", followerID, "followee_id", followeeID)
+	return nil
+}
+
+func (u *FollowUsecase) GetFollowers(userID int, pageReq domain.PostPageRequest) (*domain.FollowPage, error) {
+	if _, err := u.getActiveUser(userID); err != nil {
+		return nil, err
+	}
+
+	page, err := u.followRepo.GetFollowers(userID, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+
+	return page, nil
+}
+
+func (u *FollowUsecase) GetFollowing(userID int, pageReq domain.PostPageRequest) (*domain.FollowPage, error) {
+	if _, err := u.getActiveUser(userID); err != nil {
+		return nil, err
+	}
+
+	page, err := u.followRepo.GetFollowing(userID, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following: %w", err)
+	}
+
+	return page, nil
+}
+
+func (u *FollowUsecase) FollowCategory(userID, categoryID int) error {
+	exists, err := u.postRepo.CategoryExists(categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to check category: %w", err)
+	}
+	if !exists {
+		return errors.New("category not found")
+	}
+
+	err = u.followRepo.FollowCategory(userID, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to follow category: %w", err)
+	}
+
+	slog.Info("Category followed successfully", "user_id", userID, "category_id", categoryID)
+	return nil
+}
+
+func (u *FollowUsecase) UnfollowCategory(userID, categoryID int) error {
+	err := u.followRepo.UnfollowCategory(userID, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow category: %w", err)
+	}
+
+	slog.Info("Category unfollowed successfully", "user_id", userID, "category_id", categoryID)
+	return nil
+}
+
+func (u *FollowUsecase) GetFollowedCategories(userID int) ([]domain.Category, error) {
+	categories, err := u.followRepo.GetFollowedCategories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// getActiveUser hides banned and deactivated users as if they did not exist
+func (u *FollowUsecase) getActiveUser(userID int) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil || !user.IsActive {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
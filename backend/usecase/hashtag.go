@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// maxTagLength is the longest tag in characters; longer ones are ignored
+	maxTagLength = 50
+	// maxTagsPerPost caps how many tags one post gets; later ones are ignored
+	maxTagsPerPost = 10
+)
+
+// normalizeTag folds a tag to the form it is stored and looked up in. NFKC
+// turns full-width letters and digits into half-width ones and half-width
+// katakana into full-width ones, and the result is lower-cased, so that
+// "＃ＧＯ", "#Go" and "#go" are the same tag.
+func normalizeTag(tag string) string {
+	return strings.ToLower(norm.NFKC.String(tag))
+}
+
+// isValidTag reports whether a normalized tag could have been extracted by
+// findHashtags
+func isValidTag(tag string) bool {
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return false
+	}
+	allDigits := true
+	for _, r := range tag {
+		if !isTagRune(r) {
+			return false
+		}
+		if !unicode.IsDigit(r) {
+			allDigits = false
+		}
+	}
+	return !allDigits
+}
+
+// findHashtags returns the distinct normalized #tags in text in the order
+// they first appear. The full-width ＃ works too. A tag runs over letters,
+// digits, marks and "_", and the # must not follow a letter, digit, "&" or
+// "/" so that words like C#, HTML entities and URL fragments are not taken
+// for tags. Tags of digits only, such as "#1", are ignored.
+func findHashtags(text string) []string {
+	runes := []rune(normalizeTag(text))
+	var tags []string
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(runes) && len(tags) < maxTagsPerPost; i++ {
+		if runes[i] != '#' || (i > 0 && isTagBoundary(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isTagRune(runes[end]) {
+			end++
+		}
+
+		if tag := string(runes[i+1 : end]); isValidTag(tag) && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		i = end - 1
+	}
+	return tags
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+func isTagBoundary(r rune) bool {
+	return isTagRune(r) || strings.ContainsRune("&/#", r)
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxSearchQueryLength = 100
+	maxSearchTerms       = 5
+	searchSnippetLength  = 160
+	// snippetLeadingContext is how much text is kept before the first match
+	snippetLeadingContext = 40
+)
+
+// highlightTerms HTML-escapes text and wraps case-insensitive occurrences of
+// terms in <mark>. Matching works on runes rather than words so it also finds
+// terms inside Japanese text. When maxRunes is positive the result is cut to
+// a window of that many runes starting shortly before the first match.
+func highlightTerms(text string, terms []string, maxRunes int) string {
+	runes := []rune(text)
+	lowered := lowerRunes(runes)
+
+	needles := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		if needle := lowerRunes([]rune(term)); len(needle) > 0 {
+			needles = append(needles, needle)
+		}
+	}
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		leading := min(snippetLeadingContext, maxRunes/4)
+		if first := firstMatch(lowered, needles); first > leading {
+			start = first - leading
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		if n := matchAt(lowered[:end], i, needles); n > 0 {
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(string(runes[i : i+n])))
+			b.WriteString("</mark>")
+			i += n
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func lowerRunes(runes []rune) []rune {
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+	return lowered
+}
+
+// firstMatch returns the index of the earliest occurrence of any needle, or
+// -1 when none occurs
+func firstMatch(haystack []rune, needles [][]rune) int {
+	for i := range haystack {
+		if matchAt(haystack, i, needles) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchAt returns the length of the longest needle found at index i
+func matchAt(haystack []rune, i int, needles [][]rune) int {
+	longest := 0
+	for _, needle := range needles {
+		if len(needle) <= longest || i+len(needle) > len(haystack) {
+			continue
+		}
+		if string(haystack[i:i+len(needle)]) == string(needle) {
+			longest = len(needle)
+		}
+	}
+	return longest
+}
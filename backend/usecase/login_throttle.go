@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+// ErrTooManyLoginAttempts is returned while an account or IP address is locked.
+// It is returned for unknown email addresses too, so it reveals nothing about
+// which accounts exist.
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, please try again later")
+
+type LoginThrottleConfig struct {
+	AccountThreshold int           `envconfig:"LOGIN_ACCOUNT_THRESHOLD" default:"5"`
+	IPThreshold      int           `envconfig:"LOGIN_IP_THRESHOLD" default:"20"`
+	LockoutBase      time.Duration `envconfig:"LOGIN_LOCKOUT_BASE" default:"1m"`
+	LockoutMax       time.Duration `envconfig:"LOGIN_LOCKOUT_MAX" default:"1h"`
+	FailureWindow    time.Duration `envconfig:"LOGIN_FAILURE_WINDOW" default:"24h"`
+}
+
+// LoginThrottler tracks failed logins per account and per IP address. Once a
+// threshold is reached every further failure locks the key for twice as long
+// as the previous one, up to LockoutMax.
+type LoginThrottler struct {
+	throttleRepo *repository.LoginThrottleRepository
+	config       LoginThrottleConfig
+}
+
+func NewLoginThrottler(throttleRepo *repository.LoginThrottleRepository, config LoginThrottleConfig) *LoginThrottler {
+	return &LoginThrottler{
+		throttleRepo: throttleRepo,
+		config:       config,
+	}
+}
+
+// Check returns ErrTooManyLoginAttempts if either the account or the IP
+// address is currently locked.
+func (t *LoginThrottler) Check(email, ip string) error {
+	for _, throttleKey := range t.keys(email, ip) {
+		throttle, err := t.throttleRepo.Get(throttleKey.scope, throttleKey.key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check login throttle: %w", err)
+		}
+
+		if throttle.LockedUntil != nil && time.Now().Before(*throttle.LockedUntil) {
+			return ErrTooManyLoginAttempts
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed attempt. It reports whether this failure is
+// the one that first locked the account, which is when the unlock mail is sent.
+func (t *LoginThrottler) RecordFailure(email, ip string) (bool, error) {
+	accountLocked := false
+
+	for _, throttleKey := range t.keys(email, ip) {
+		failedAttempts, err := t.throttleRepo.RecordFailure(throttleKey.scope, throttleKey.key, t.config.FailureWindow)
+		if err != nil {
+			return false, fmt.Errorf("failed to record login failure: %w", err)
+		}
+
+		if failedAttempts < throttleKey.threshold {
+			continue
+		}
+
+		lockout := t.lockoutDuration(failedAttempts - throttleKey.threshold)
+		err = t.throttleRepo.Lock(throttleKey.scope, throttleKey.key, time.Now().Add(lockout))
+		if err != nil {
+			return false, fmt.Errorf("failed to lock login: %w", err)
+		}
+
+		slog.Warn("Login locked after repeated failures", "scope", throttleKey.scope, "key", throttleKey.key, "failed_attempts", failedAttempts, "lockout", lockout)
+
+		if throttleKey.scope == domain.LoginThrottleScopeAccount && failedAttempts == throttleKey.threshold {
+			accountLocked = true
+		}
+	}
+
+	return accountLocked, nil
+}
+
+// Reset clears the failure counter and any lock on the account. The IP counter
+// is left alone so that one valid login cannot be used to keep guessing
+// other accounts from the same address.
+func (t *LoginThrottler) Reset(email string) error {
+	err := t.throttleRepo.Reset(domain.LoginThrottleScopeAccount, normalizeLoginEmail(email))
+	if err != nil {
+		return fmt.Errorf("failed to reset login throttle: %w", err)
+	}
+	return nil
+}
+
+func (t *LoginThrottler) lockoutDuration(excessFailures int) time.Duration {
+	lockout := t.config.LockoutBase
+	for i := 0; i < excessFailures && lockout < t.config.LockoutMax; i++ {
+		lockout *= 2
+	}
+	if lockout > t.config.LockoutMax {
+		lockout = t.config.LockoutMax
+	}
+	return lockout
+}
+
+type loginThrottleKey struct {
+	scope     domain.LoginThrottleScope
+	key       string
+	threshold int
+}
+
+func (t *LoginThrottler) keys(email, ip string) []loginThrottleKey {
+	keys := []loginThrottleKey{{
+		scope:     domain.LoginThrottleScopeAccount,
+		key:       normalizeLoginEmail(email),
+		threshold: t.config.AccountThreshold,
+	}}
+
+	if ip != "" {
+		keys = append(keys, loginThrottleKey{
+			scope:     domain.LoginThrottleScopeIP,
+			key:       ip,
+			threshold: t.config.IPThreshold,
+		})
+	}
+
+	return keys
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
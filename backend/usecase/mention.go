@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxMentionsPerContent caps how many different users one post or reply can
+// mention, and so how many lookups and notifications it causes
+const maxMentionsPerContent = 20
+
+// mentionMatch is one @name in a text. Start and End are rune offsets; Start
+// is at the @ and End is just after the name.
+type mentionMatch struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// findMentions returns the @display_name mentions in text. The full-width ＠
+// works too. A name runs over letters, digits, marks, "_", "-" and "." (so
+// Japanese names work) without a trailing "." or "-", and the @ must not
+// follow a letter or digit so that email addresses are not taken for
+// mentions. Display names with spaces or other characters can therefore not
+// be mentioned, and in Japanese text the name has to be followed by a space
+// or punctuation.
+func findMentions(text string) []mentionMatch {
+	runes := []rune(text)
+	var matches []mentionMatch
+
+	for i := 0; i < len(runes); i++ {
+		if (runes[i] != '@' && runes[i] != '＠') || (i > 0 && isMentionBoundary(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isMentionRune(runes[end]) {
+			end++
+		}
+		for end > i+1 && (runes[end-1] == '.' || runes[end-1] == '-') {
+			end--
+		}
+
+		if name := string(runes[i+1 : end]); name != "" && end-i-1 <= maxDisplayNameLength {
+			matches = append(matches, mentionMatch{Name: name, Start: i, End: end})
+		}
+		i = end - 1
+	}
+	return matches
+}
+
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || strings.ContainsRune("_-.", r)
+}
+
+func isMentionBoundary(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+type NotificationUsecase struct {
+	notificationRepo *repository.NotificationRepository
+}
+
+func NewNotificationUsecase(notificationRepo *repository.NotificationRepository) *NotificationUsecase {
+	return &NotificationUsecase{
+		notificationRepo: notificationRepo,
+	}
+}
+
+// Notify saves a notification unless it is about the recipient's own action
+// or they turned its type off. Failures are only logged so that they never
+// fail the action being notified about.
+func (u *NotificationUsecase) Notify(notification *domain.Notification) {
+	if notification.ActorID != nil && *notification.ActorID == notification.UserID {
+		return
+	}
+
+	created, err := u.notificationRepo.Create(notification)
+	if err != nil {
+		slog.Error("Failed to create notification", "user_id", notification.UserID, "type", notification.Type, "error", err)
+		return
+	}
+
+	if created {
+		slog.Info("Notification created successfully", "notification_id", notification.ID, "user_id", notification.UserID, "type", notification.Type)
+	}
+}
+
+// GetNotifications lists a user's notifications, or only unread ones, with
+// their total unread count
+func (u *NotificationUsecase) GetNotifications(userID int, unreadOnly bool, pageReq domain.PostPageRequest) (*domain.NotificationPage, error) {
+	page, err := u.notificationRepo.GetByUserID(userID, unreadOnly, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	return page, nil
+}
+
+func (u *NotificationUsecase) GetUnreadCount(userID int) (int, error) {
+	count, err := u.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+func (u *NotificationUsecase) MarkRead(userID, notificationID int) error {
+	err := u.notificationRepo.MarkRead(userID, notificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("notification not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+
+	return nil
+}
+
+// MarkAllRead returns how many notifications were unread
+func (u *NotificationUsecase) MarkAllRead(userID int) (int64, error) {
+	count, err := u.notificationRepo.MarkAllRead(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+
+	slog.Info("Notifications marked as read", "user_id", userID, "count", count)
+	return count, nil
+}
+
+// GetPreferences returns whether the user receives each notification type
+func (u *NotificationUsecase) GetPreferences(userID int) ([]domain.NotificationPreference, error) {
+	disabled, err := u.notificationRepo.GetDisabledTypes(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	preferences := make([]domain.NotificationPreference, 0, len(domain.NotificationTypes))
+	for _, notificationType := range domain.NotificationTypes {
+		enabled := true
+		for _, disabledType := range disabled {
+			if disabledType == notificationType {
+				enabled = false
+				break
+			}
+		}
+		preferences = append(preferences, domain.NotificationPreference{Type: notificationType, Enabled: enabled})
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences turns the given notification types on or off, leaving
+// the others as they are, and returns the resulting preferences
+func (u *NotificationUsecase) UpdatePreferences(userID int, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error) {
+	for _, preference := range preferences {
+		if !preference.Type.IsValid() {
+			return nil, fmt.Errorf("unknown notification type: %s", preference.Type)
+		}
+	}
+
+	for _, preference := range preferences {
+		err := u.notificationRepo.SetPreference(userID, preference.Type, preference.Enabled)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+		}
+	}
+
+	slog.Info("Notification preferences updated successfully", "user_id", userID)
+	return u.GetPreferences(userID)
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+	"posting-app/domain"
+	"posting-app/infrastructure"
+	"posting-app/repository"
+)
+
+// ErrOIDCNotConfigured is returned when no OIDC issuer is configured
+var ErrOIDCNotConfigured = errors.New("OIDC login is not configured")
+
+// ErrTooManyPendingLogins is returned when a client has started too many
+// logins that have not completed or expired yet
+var ErrTooManyPendingLogins = errors.New("too many logins in progress, try again later")
+
+const (
+	oidcLoginStateDuration = 10 * time.Minute
+	maxPendingOIDCLogins   = 10 // per client IP within oidcLoginStateDuration
+	maxDisplayNameLength   = 100
+	maxDisplayNameAttempts = 10
+)
+
+type OIDCUsecase struct {
+	identityRepo *repository.IdentityRepository
+	userRepo     *repository.UserRepository
+	provider     *infrastructure.OIDCProvider
+	authUsecase  *AuthUsecase
+}
+
+func NewOIDCUsecase(
+	identityRepo *repository.IdentityRepository,
+	userRepo *repository.UserRepository,
+	provider *infrastructure.OIDCProvider,
+	authUsecase *AuthUsecase,
+) *OIDCUsecase {
+	return &OIDCUsecase{
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		provider:     provider,
+		authUsecase:  authUsecase,
+	}
+}
+
+// OIDCLogin is a started login. State has to be kept by the browser that
+// started it, e.g. in a cookie, and be presented again with the callback.
+type OIDCLogin struct {
+	AuthorizationURL string
+	State            string
+	ExpiresAt        time.Time
+}
+
+// BeginLogin starts an authorization code flow with PKCE and returns the URL
+// to send the browser to. The nonce and code verifier stay server side.
+func (u *OIDCUsecase) BeginLogin(client ClientInfo) (*OIDCLogin, error) {
+	if !u.provider.Enabled() {
+		return nil, ErrOIDCNotConfigured
+	}
+
+	pending, err := u.identityRepo.CountPendingLoginStates(client.IPAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count login states: %w", err)
+	}
+	if pending >= maxPendingOIDCLogins {
+		return nil, ErrTooManyPendingLogins
+	}
+
+	state, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := generateRandomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	codeVerifier, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	expiresAt := time.Now().Add(oidcLoginStateDuration)
+	err = u.identityRepo.CreateLoginState(&domain.OIDCLoginState{
+		StateHash:    hashToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		IPAddress:    client.IPAddress,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save login state: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	authURL, err := u.provider.AuthorizationURL(state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCLogin{AuthorizationURL: authURL, State: state, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteLogin finishes the flow with the code and state the provider sent
+// back. browserState is the state kept by the calling browser; it must match,
+// or a victim could be made to complete a login the attacker started and end
+// up in the attacker's account. Users with two-factor authentication get an
+// MFA challenge, exactly as with password login.
+func (u *OIDCUsecase) CompleteLogin(code, state, browserState string, client ClientInfo) (*domain.User, *AuthTokens, error) {
+	if !u.provider.Enabled() {
+		return nil, nil, ErrOIDCNotConfigured
+	}
+
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, nil, errors.New("login state does not belong to this browser")
+	}
+
+	loginState, err := u.identityRepo.ConsumeLoginState(hashToken(state))
+	if err != nil {
+		return nil, nil, errors.New("invalid or expired login state")
+	}
+
+	idToken, err := u.provider.Exchange(code, loginState.CodeVerifier)
+	if err != nil {
+		slog.Warn("OIDC code exchange failed", "error", err)
+		return nil, nil, errors.New("external login failed")
+	}
+
+	if idToken.Nonce != loginState.Nonce {
+		slog.Warn("OIDC nonce mismatch", "issuer", idToken.Issuer, "subject", idToken.Subject)
+		return nil, nil, errors.New("external login failed")
+	}
+
+	user, err := u.resolveUser(idToken, client.Language)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.IsActive {
+		return nil, nil, errors.New("account is deactivated")
+	}
+
+	enabled, err := u.authUsecase.twoFactorUsecase.IsEnabled(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enabled {
+		tokens, err := u.authUsecase.twoFactorChallenge(user)
+		if err != nil {
+			return nil, nil, err
+		}
+		return user, tokens, nil
+	}
+
+	tokens, err := u.authUsecase.startSession(user, client, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("User logged in with OIDC", "user_id", user.ID, "issuer", idToken.Issuer)
+	return user, tokens, nil
+}
+
+// resolveUser finds the user linked to the identity. Unknown identities are
+// linked to the account with the same email if the provider verified that
+// address, and otherwise get a new account.
+func (u *OIDCUsecase) resolveUser(idToken *infrastructure.OIDCIDToken, lang string) (*domain.User, error) {
+	var email *string
+	if idToken.Email != "" {
+		email = &idToken.Email
+	}
+
+	identity, err := u.identityRepo.GetByIssuerAndSubject(idToken.Issuer, idToken.Subject)
+	if err == nil {
+		err = u.identityRepo.UpdateLastLogin(identity.ID, email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update identity: %w", err)
+		}
+		user, err := u.userRepo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	if idToken.Email == "" {
+		return nil, errors.New("the identity provider did not share an email address")
+	}
+
+	user, err := u.userRepo.GetByEmail(idToken.Email)
+	if err == nil {
+		if !idToken.EmailVerified {
+			// Linking on an unverified address would let anyone who can
+			// register it at the provider take over the account
+			return nil, errors.New("an account with this email already exists")
+		}
+	} else {
+		user, err = u.createUser(idToken)
+		if err != nil {
+			return nil, err
+		}
+
+		if !user.EmailVerified {
+			err = u.authUsecase.sendVerification(user, lang)
+			if err != nil {
+				slog.Error("Failed to send verification email", "user_id", user.ID, "error", err)
+			}
+		}
+	}
+
+	err = u.identityRepo.Create(&domain.Identity{
+		UserID:  user.ID,
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	slog.Info("OIDC identity linked", "user_id", user.ID, "issuer", idToken.Issuer)
+	return user, nil
+}
+
+func (u *OIDCUsecase) createUser(idToken *infrastructure.OIDCIDToken) (*domain.User, error) {
+	// The account has no usable password until the user resets it
+	randomPassword, err := generateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	base := oidcDisplayNameBase(idToken)
+
+	// Try base, then base_2 ... base_5, then random suffixes, and let the
+	// unique constraint decide so concurrent sign-ups cannot take the same name
+	for attempt := 1; attempt <= maxDisplayNameAttempts; attempt++ {
+		suffix := ""
+		if attempt > 5 {
+			random, err := generateRandomToken(3)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate display name: %w", err)
+			}
+			suffix = "_" + random
+		} else if attempt > 1 {
+			suffix = "_" + strconv.Itoa(attempt)
+		}
+
+		user := &domain.User{
+			Email:              idToken.Email,
+			PasswordHash:       string(hashedPassword),
+			DisplayName:        displayNameCandidate(base, suffix),
+			Role:               domain.UserRoleUser,
+			SubscriptionStatus: domain.UserSubscriptionStatusInactive,
+			IsActive:           true,
+			EmailVerified:      idToken.EmailVerified,
+		}
+
+		err = u.userRepo.Create(user)
+		if errors.Is(err, repository.ErrDisplayNameTaken) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+
+		slog.Info("User created from OIDC identity", "user_id", user.ID, "display_name", user.DisplayName)
+		return user, nil
+	}
+
+	return nil, errors.New("could not find an available display name")
+}
+
+func oidcDisplayNameBase(idToken *infrastructure.OIDCIDToken) string {
+	for _, candidate := range []string{
+		idToken.Name,
+		idToken.PreferredUsername,
+		strings.SplitN(idToken.Email, "@", 2)[0],
+	} {
+		candidate = strings.TrimSpace(candidate)
+		if candidate != "" {
+			return candidate
+		}
+	}
+	return "user"
+}
+
+// displayNameCandidate appends suffix, trimming the base so the result fits
+// the display_name column
+func displayNameCandidate(base, suffix string) string {
+	for utf8.RuneCountInString(base)+len(suffix) > maxDisplayNameLength {
+		_, size := utf8.DecodeLastRuneInString(base)
+		base = base[:len(base)-size]
+	}
+
+	return base + suffix
+}
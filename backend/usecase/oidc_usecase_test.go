@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"testing"
+
+	"posting-app/infrastructure"
+)
+
+func TestCompleteLoginRequiresStateOfThisBrowser(t *testing.T) {
+	provider := infrastructure.NewOIDCProvider(infrastructure.OIDCConfig{
+		Issuer:   "https://issuer.example",
+		ClientID: "posting-app",
+	})
+	// No repositories: a mismatched state must be refused before the login
+	// state is looked up or consumed
+	u := NewOIDCUsecase(nil, nil, provider, nil)
+
+	for _, browserState := range []string{"", "state-of-another-login"} {
+		if _, _, err := u.CompleteLogin("code", "attacker-state", browserState, ClientInfo{}); err == nil {
+			t.Errorf("expected browser state %q to be refused", browserState)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+// PersonalAccessTokenPrefix marks a bearer token as a personal access token
+// rather than a JWT
+const PersonalAccessTokenPrefix = "pat_"
+
+const maxPersonalAccessTokensPerUser = 50
+
+type PersonalAccessTokenUsecase struct {
+	tokenRepo *repository.PersonalAccessTokenRepository
+	userRepo  *repository.UserRepository
+}
+
+func NewPersonalAccessTokenUsecase(tokenRepo *repository.PersonalAccessTokenRepository, userRepo *repository.UserRepository) *PersonalAccessTokenUsecase {
+	return &PersonalAccessTokenUsecase{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Create issues a new token. The plain token is returned only here; only its
+// hash is stored.
+func (u *PersonalAccessTokenUsecase) Create(userID int, name string, scopes []domain.TokenScope, expiresInDays *int) (*domain.PersonalAccessToken, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", errors.New("at least one scope is required")
+	}
+
+	seen := make(map[domain.TokenScope]bool)
+	uniqueScopes := make([]domain.TokenScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !isValidTokenScope(scope) {
+			return nil, "", fmt.Errorf("unknown scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			uniqueScopes = append(uniqueScopes, scope)
+		}
+	}
+
+	existing, err := u.tokenRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get tokens: %w", err)
+	}
+	if len(existing) >= maxPersonalAccessTokensPerUser {
+		return nil, "", errors.New("too many personal access tokens")
+	}
+
+	secret, err := generateRandomToken(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	plainToken := PersonalAccessTokenPrefix + secret
+
+	token := &domain.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: plainToken[:len(PersonalAccessTokenPrefix)+8],
+		TokenHash:   hashToken(plainToken),
+		Scopes:      uniqueScopes,
+	}
+
+	if expiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *expiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	err = u.tokenRepo.Create(token)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create token: %w", err)
+	}
+
+	slog.Info("Personal access token created", "user_id", userID, "token_id", token.ID)
+	return token, plainToken, nil
+}
+
+func (u *PersonalAccessTokenUsecase) List(userID int) ([]domain.PersonalAccessToken, error) {
+	return u.tokenRepo.GetByUserID(userID)
+}
+
+func (u *PersonalAccessTokenUsecase) Revoke(userID, tokenID int) error {
+	revoked, err := u.tokenRepo.Revoke(tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	if !revoked {
+		return errors.New("token not found")
+	}
+
+	slog.Info("Personal access token revoked", "user_id", userID, "token_id", tokenID)
+	return nil
+}
+
+// Authenticate resolves a personal access token to a principal limited to the
+// token's scopes. Tokens of banned or deactivated users are rejected.
+func (u *PersonalAccessTokenUsecase) Authenticate(plainToken string) (*Principal, error) {
+	token, err := u.tokenRepo.GetActiveByTokenHash(hashToken(plainToken))
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	user, err := u.userRepo.GetByID(token.UserID)
+	if err != nil || !user.IsActive {
+		return nil, errors.New("invalid token")
+	}
+
+	err = u.tokenRepo.UpdateLastUsed(token.ID)
+	if err != nil {
+		slog.Error("Failed to update token last used time", "token_id", token.ID, "error", err)
+	}
+
+	return &Principal{
+		User: &domain.User{
+			ID:    user.ID,
+			Email: user.Email,
+			Role:  user.Role,
+		},
+		Scopes:                token.Scopes,
+		PersonalAccessTokenID: token.ID,
+	}, nil
+}
+
+func isPersonalAccessToken(tokenString string) bool {
+	return strings.HasPrefix(tokenString, PersonalAccessTokenPrefix)
+}
+
+func isValidTokenScope(scope domain.TokenScope) bool {
+	for _, valid := range domain.TokenScopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
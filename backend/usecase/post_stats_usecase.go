@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+const (
+	// defaultStatsDays and maxStatsDays bound the daily series of the stats
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+	// statsTopPosts is how many of the most viewed posts a summary lists
+	statsTopPosts = 5
+)
+
+type PostStatsUsecase struct {
+	viewRepo *repository.PostViewRepository
+	postRepo *repository.PostRepository
+}
+
+func NewPostStatsUsecase(viewRepo *repository.PostViewRepository, postRepo *repository.PostRepository) *PostStatsUsecase {
+	return &PostStatsUsecase{
+		viewRepo: viewRepo,
+		postRepo: postRepo,
+	}
+}
+
+// GetPostStats returns the statistics of one of the user's posts with a
+// daily series over the last days days, today included
+func (u *PostStatsUsecase) GetPostStats(userID, postID, days int) (*domain.PostStats, error) {
+	post, err := u.postRepo.GetByID(postID)
+	if err != nil || post.AuthorID != userID {
+		return nil, errors.New("post not found")
+	}
+
+	stats, err := u.viewRepo.GetPostStats(postID, statsSince(days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetSummary sums the statistics of all of the user's posts except drafts
+func (u *PostStatsUsecase) GetSummary(userID, days int) (*domain.PostStatsSummary, error) {
+	summary, err := u.viewRepo.GetAuthorStats(userID, statsSince(days), statsTopPosts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post stats: %w", err)
+	}
+
+	return summary, nil
+}
+
+// statsSince is the UTC midnight days-1 days before today, so that a series
+// from it has days entries
+func statsSince(days int) time.Time {
+	if days < 1 {
+		days = defaultStatsDays
+	}
+	if days > maxStatsDays {
+		days = maxStatsDays
+	}
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+}
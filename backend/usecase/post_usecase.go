@@ -1,33 +1,89 @@
 package usecase
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"posting-app/domain"
+	"posting-app/infrastructure"
 	"posting-app/repository"
 )
 
+// ReplyConfig limits reply threads. MaxDepth is how many levels of replies
+// may be nested under a reply to the post, and EditWindow is how long after
+// posting the author may still edit a reply.
+type ReplyConfig struct {
+	MaxDepth   int           `envconfig:"REPLY_MAX_DEPTH" default:"5"`
+	EditWindow time.Duration `envconfig:"REPLY_EDIT_WINDOW" default:"15m"`
+}
+
+// ReactionConfig is the set of reactions users can add to posts and
+// replies. The like toggle always uses domain.ReactionLike.
+type ReactionConfig struct {
+	Allowed []string `envconfig:"REACTIONS" default:"like,love,laugh,wow,sad,celebrate"`
+}
+
+// TagConfig sets TrendingWindow, how far back trending tags look for
+// published posts
+type TagConfig struct {
+	TrendingWindow time.Duration `envconfig:"TAG_TRENDING_WINDOW" default:"24h"`
+}
+
+// maxTrendingTags caps how many trending tags one request returns
+const maxTrendingTags = 50
+
+// ErrUnsupportedReaction is returned for reactions not in ReactionConfig
+var ErrUnsupportedReaction = errors.New("unsupported reaction")
+
 type PostUsecase struct {
-	postRepo *repository.PostRepository
-	userRepo *repository.UserRepository
+	postRepo            *repository.PostRepository
+	userRepo            *repository.UserRepository
+	revisionRepo        *repository.PostRevisionRepository
+	notificationUsecase *NotificationUsecase
+	eventUsecase        *EventUsecase
+	viewRecorder        *ViewRecorder
+	ownerTagger         *infrastructure.OwnerTagger
+	replyConfig         ReplyConfig
+	reactionConfig      ReactionConfig
+	tagConfig           TagConfig
 }
 
-func NewPostUsecase(postRepo *repository.PostRepository, userRepo *repository.UserRepository) *PostUsecase {
+// NewPostUsecase creates a PostUsecase. viewRecorder and ownerTagger may be
+// nil in processes that do not serve posts and replies to readers, such as
+// the batch jobs.
+func NewPostUsecase(postRepo *repository.PostRepository, userRepo *repository.UserRepository, revisionRepo *repository.PostRevisionRepository, notificationUsecase *NotificationUsecase, eventUsecase *EventUsecase, viewRecorder *ViewRecorder, ownerTagger *infrastructure.OwnerTagger, replyConfig ReplyConfig, reactionConfig ReactionConfig, tagConfig TagConfig) *PostUsecase {
 	return &PostUsecase{
-		postRepo: postRepo,
-		userRepo: userRepo,
+		postRepo:            postRepo,
+		userRepo:            userRepo,
+		revisionRepo:        revisionRepo,
+		notificationUsecase: notificationUsecase,
+		eventUsecase:        eventUsecase,
+		viewRecorder:        viewRecorder,
+		ownerTagger:         ownerTagger,
+		replyConfig:         replyConfig,
+		reactionConfig:      reactionConfig,
+		tagConfig:           tagConfig,
 	}
 }
 
-func (u *PostUsecase) CreatePost(userID int, title, content string, thumbnailURL *string, categoryIDs []int, groupID *int) (*domain.Post, error) {
+// CreatePost submits a post for moderation, or saves it as a draft only its
+// author can see. An approved post with publishAt stays hidden until then.
+func (u *PostUsecase) CreatePost(userID int, title, content string, contentFormat domain.ContentFormat, thumbnailURL *string, categoryIDs []int, groupID *int, draft bool, publishAt *time.Time) (*domain.Post, error) {
 	// Check if user has active subscription
 	user, err := u.userRepo.GetByID(userID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
 	if user.SubscriptionStatus != domain.UserSubscriptionStatusActive {
 		return nil, errors.New("active subscription required to create posts")
 	}
@@ -37,6 +93,10 @@ func (u *PostUsecase) CreatePost(userID int, title, content string, thumbnailURL
 		return nil, errors.New("maximum 5 categories allowed")
 	}
 
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		return nil, errors.New("publish_at must be in the future")
+	}
+
 	// If groupID is provided, check if user is member of the group
 	if groupID != nil {
 		isMember, err := u.postRepo.IsGroupMember(*groupID, userID)
@@ -48,13 +108,26 @@ func (u *PostUsecase) CreatePost(userID int, title, content string, thumbnailURL
 		}
 	}
 
+	contentHTML, err := infrastructure.RenderContent(contentFormat, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
+	status := domain.PostStatusPending // Requires admin approval
+	if draft {
+		status = domain.PostStatusDraft
+	}
+
 	post := &domain.Post{
-		Title:        title,
-		Content:      content,
-		ThumbnailURL: thumbnailURL,
-		AuthorID:     userID,
-		Status:       domain.PostStatusPending, // Requires admin approval
-		GroupID:      groupID,
+		Title:         title,
+		Content:       content,
+		ContentFormat: contentFormat,
+		ContentHTML:   contentHTML,
+		ThumbnailURL:  thumbnailURL,
+		AuthorID:      userID,
+		Status:        status,
+		GroupID:       groupID,
+		PublishAt:     publishAt,
 	}
 
 	err = u.postRepo.Create(post)
@@ -70,17 +143,32 @@ func (u *PostUsecase) CreatePost(userID int, title, content string, thumbnailURL
 		}
 	}
 
+	err = u.postRepo.SetPostTags(post.ID, findHashtags(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set tags: %w", err)
+	}
+
+	// Mentioned users are notified once the post is published
+	if _, err := u.setMentions(post, nil, content); err != nil {
+		return nil, err
+	}
+
 	// Get the post with author info
 	createdPost, err := u.postRepo.GetByID(post.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get created post: %w", err)
 	}
 
-	slog.Info("Post created successfully", "post_id", post.ID, "user_id", userID)
+	err = u.recordRevision(createdPost, userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	slog.Info("Post created successfully", "post_id", post.ID, "user_id", userID, "status", status)
 	return createdPost, nil
 }
 
-func (u *PostUsecase) UpdatePost(userID, postID int, title, content string, thumbnailURL *string, categoryIDs []int, groupID *int) (*domain.Post, error) {
+func (u *PostUsecase) UpdatePost(userID, postID int, title, content string, contentFormat domain.ContentFormat, thumbnailURL *string, categoryIDs []int, groupID *int, publishAt *time.Time) (*domain.Post, error) {
 	post, err := u.postRepo.GetByID(postID)
 	if err != nil {
 		return nil, errors.New("post not found")
@@ -90,9 +178,9 @@ func (u *PostUsecase) UpdatePost(userID, postID int, title, content string, thum
 		return nil, errors.New("you can only edit your own posts")
 	}
 
-	// Can only edit if post is pending
-	if post.Status != domain.PostStatusPending {
-		return nil, errors.New("can only edit posts that are pending approval")
+	// Can only edit drafts and pending posts
+	if !isEditable(post) {
+		return nil, errors.New("can only edit drafts or posts that are pending approval")
 	}
 
 	// Validate category limit
@@ -100,6 +188,10 @@ func (u *PostUsecase) UpdatePost(userID, postID int, title, content string, thum
 		return nil, errors.New("maximum 5 categories allowed")
 	}
 
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		return nil, errors.New("publish_at must be in the future")
+	}
+
 	// If groupID is provided, check if user is member of the group
 	if groupID != nil {
 		isMember, err := u.postRepo.IsGroupMember(*groupID, userID)
@@ -111,10 +203,18 @@ func (u *PostUsecase) UpdatePost(userID, postID int, title, content string, thum
 		}
 	}
 
+	contentHTML, err := infrastructure.RenderContent(contentFormat, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
 	post.Title = title
 	post.Content = content
+	post.ContentFormat = contentFormat
+	post.ContentHTML = contentHTML
 	post.ThumbnailURL = thumbnailURL
 	post.GroupID = groupID
+	post.PublishAt = publishAt
 
 	err = u.postRepo.Update(post)
 	if err != nil {
@@ -127,16 +227,82 @@ func (u *PostUsecase) UpdatePost(userID, postID int, title, content string, thum
 		return nil, fmt.Errorf("failed to update categories: %w", err)
 	}
 
+	err = u.postRepo.SetPostTags(post.ID, findHashtags(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	if _, err := u.setMentions(post, nil, content); err != nil {
+		return nil, err
+	}
+
 	// Get updated post
 	updatedPost, err := u.postRepo.GetByID(postID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get updated post: %w", err)
 	}
 
+	err = u.recordRevision(updatedPost, userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record revision: %w", err)
+	}
+
 	slog.Info("Post updated successfully", "post_id", postID, "user_id", userID)
 	return updatedPost, nil
 }
 
+// SubmitDraft sends a draft to moderation
+func (u *PostUsecase) SubmitDraft(userID, postID int) (*domain.Post, error) {
+	post, err := u.postRepo.GetByID(postID)
+	if err != nil || post.AuthorID != userID {
+		return nil, errors.New("post not found")
+	}
+
+	if post.Status != domain.PostStatusDraft {
+		return nil, errors.New("post is not a draft")
+	}
+
+	if post.PublishAt != nil && !post.PublishAt.After(time.Now()) {
+		return nil, errors.New("publish_at must be in the future")
+	}
+
+	err = u.postRepo.UpdateStatus(postID, domain.PostStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit draft: %w", err)
+	}
+	post.Status = domain.PostStatusPending
+
+	slog.Info("Draft submitted successfully", "post_id", postID, "user_id", userID)
+	return post, nil
+}
+
+func (u *PostUsecase) GetUserDrafts(userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	page, err := u.postRepo.GetDraftsByUserID(userID, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
+	}
+
+	return page, nil
+}
+
+// PublishDuePosts publishes approved posts whose publish_at has passed. It
+// is run periodically by the batch publisher job.
+func (u *PostUsecase) PublishDuePosts() (int, error) {
+	postIDs, err := u.postRepo.PublishDue()
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish due posts: %w", err)
+	}
+
+	for _, postID := range postIDs {
+		if post, err := u.postRepo.GetByID(postID); err == nil {
+			u.publishGroupPost(post)
+			u.notifyMentions(post, nil, post.AuthorID, false)
+		}
+		slog.Info("Scheduled post published", "post_id", postID)
+	}
+	return len(postIDs), nil
+}
+
 func (u *PostUsecase) DeletePost(userID, postID int) error {
 	post, err := u.postRepo.GetByID(postID)
 	if err != nil {
@@ -162,79 +328,136 @@ func (u *PostUsecase) DeletePost(userID, postID int) error {
 	return nil
 }
 
+// GetPost returns a post the user can see and counts the view, unless it
+// is the author's own
 func (u *PostUsecase) GetPost(postID int, userID *int) (*domain.Post, error) {
-	post, err := u.postRepo.GetByID(postID)
+	post, err := u.getVisiblePost(postID, userID)
 	if err != nil {
-		return nil, errors.New("post not found")
+		return nil, err
 	}
 
-	// Only return approved posts
-	if post.Status != domain.PostStatusApproved {
-		return nil, errors.New("post not available")
+	if userID != nil && *userID != post.AuthorID && u.viewRecorder != nil {
+		u.viewRecorder.Record(postID, *userID)
 	}
 
-	// If it's a group post, check if user is member of the group
-	if post.GroupID != nil {
-		if userID == nil {
-			return nil, errors.New("authentication required")
-		}
-		isMember, err := u.postRepo.IsGroupMember(*post.GroupID, *userID)
+	// Set the user's own reactions and bookmark if user is provided
+	if userID != nil {
+		err := u.postRepo.LoadViewerReactions(post, *userID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check group membership: %w", err)
+			return nil, fmt.Errorf("failed to load reactions: %w", err)
 		}
-		if !isMember {
-			return nil, errors.New("you are not a member of this group")
-		}
-	}
-
-	// Set like status if user is provided
-	if userID != nil {
-		isLiked, err := u.postRepo.IsLikedByUser(postID, *userID)
+		post.IsBookmarked, err = u.postRepo.IsBookmarkedByUser(postID, *userID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to check like status: %w", err)
+			return nil, fmt.Errorf("failed to check bookmark: %w", err)
 		}
-		post.IsLiked = isLiked
 	}
 
 	return post, nil
 }
 
-func (u *PostUsecase) GetApprovedPosts(page, limit int, userID *int) ([]*domain.Post, int, error) {
-	posts, total, err := u.postRepo.GetApproved(page, limit)
+func (u *PostUsecase) GetApprovedPosts(pageReq domain.PostPageRequest, userID *int) (*domain.PostPage, error) {
+	page, err := u.postRepo.GetApproved(pageReq, userID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get posts: %w", err)
+		return nil, fmt.Errorf("failed to get posts: %w", err)
 	}
 
-	// Set like status if user is provided
-	if userID != nil {
-		for _, post := range posts {
-			isLiked, err := u.postRepo.IsLikedByUser(post.ID, *userID)
-			if err != nil {
-				return nil, 0, fmt.Errorf("failed to check like status: %w", err)
-			}
-			post.IsLiked = isLiked
-		}
+	return page, nil
+}
+
+func (u *PostUsecase) GetUserPosts(userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	page, err := u.postRepo.GetByUserID(userID, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user posts: %w", err)
+	}
+
+	return page, nil
+}
+
+// GetFeed is the user's home timeline: posts from followed authors and
+// categories and from the user's groups, newest first
+func (u *PostUsecase) GetFeed(userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	page, err := u.postRepo.GetFeed(userID, pageReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
 	}
 
-	return posts, total, nil
+	return page, nil
 }
 
-func (u *PostUsecase) GetUserPosts(userID, page, limit int) ([]*domain.Post, int, error) {
-	posts, total, err := u.postRepo.GetByUserID(userID, page, limit)
+// GetTagPosts lists the published posts tagged with tag, which may be given
+// with its # and in any width or case
+func (u *PostUsecase) GetTagPosts(tag string, pageReq domain.PostPageRequest, userID *int) (*domain.PostPage, error) {
+	tag = strings.TrimPrefix(normalizeTag(strings.TrimSpace(tag)), "#")
+	if !isValidTag(tag) {
+		return nil, errors.New("invalid tag")
+	}
+
+	page, err := u.postRepo.GetByTag(tag, pageReq, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	return page, nil
+}
+
+// GetTrendingTags returns up to limit tags of public posts published within
+// TagConfig.TrendingWindow
+func (u *PostUsecase) GetTrendingTags(limit int) ([]domain.TrendingTag, error) {
+	if limit < 1 || limit > maxTrendingTags {
+		limit = maxTrendingTags
+	}
+
+	tags, err := u.postRepo.GetTrendingTags(time.Now().Add(-u.tagConfig.TrendingWindow), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (u *PostUsecase) SearchPosts(query string, params domain.PostSearchParams, page, limit int, userID *int) ([]*domain.PostSearchResult, int, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, 0, errors.New("search query is required")
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, 0, fmt.Errorf("search query must be at most %d characters", maxSearchQueryLength)
+	}
+	if len(terms) > maxSearchTerms {
+		return nil, 0, fmt.Errorf("search query must have at most %d words", maxSearchTerms)
+	}
+	if params.From != nil && params.Until != nil && !params.From.Before(*params.Until) {
+		return nil, 0, errors.New("from must be before to")
+	}
+	params.Terms = terms
+
+	results, total, err := u.postRepo.Search(params, userID, page, limit)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get user posts: %w", err)
+		return nil, 0, fmt.Errorf("failed to search posts: %w", err)
 	}
 
-	return posts, total, nil
+	for _, result := range results {
+		result.TitleHighlighted = highlightTerms(result.Post.Title, terms, 0)
+		result.Snippet = highlightTerms(result.Post.Content, terms, searchSnippetLength)
+	}
+
+	return results, total, nil
 }
 
-func (u *PostUsecase) CreateReply(userID, postID int, content string, isAnonymous bool) (*domain.Reply, error) {
+// CreateReply adds a reply to a post, or to another reply of the same post
+// when parentReplyID is set. The author is recorded even for anonymous
+// replies so that they can later be edited and deleted.
+func (u *PostUsecase) CreateReply(userID, postID int, parentReplyID *int, content string, contentFormat domain.ContentFormat, isAnonymous bool) (*domain.Reply, error) {
 	// Check if user has active subscription
 	user, err := u.userRepo.GetByID(userID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
 	if user.SubscriptionStatus != domain.UserSubscriptionStatusActive {
 		return nil, errors.New("active subscription required to create replies")
 	}
@@ -245,20 +468,63 @@ func (u *PostUsecase) CreateReply(userID, postID int, content string, isAnonymou
 		return nil, errors.New("post not found")
 	}
 
-	if post.Status != domain.PostStatusApproved {
+	if !post.IsPublished() {
 		return nil, errors.New("can only reply to approved posts")
 	}
 
-	var authorID *int
-	if !isAnonymous {
-		authorID = &userID
+	if post.GroupID != nil {
+		isMember, err := u.postRepo.IsGroupMember(*post.GroupID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check group membership: %w", err)
+		}
+		if !isMember {
+			return nil, errors.New("you are not a member of this group")
+		}
+	}
+
+	depth := 0
+	var parent *domain.Reply
+	if parentReplyID != nil {
+		parent, err = u.postRepo.GetReplyByID(*parentReplyID)
+		if err != nil || parent.PostID != postID {
+			return nil, errors.New("parent reply not found")
+		}
+		if parent.IsDeleted {
+			return nil, errors.New("cannot reply to a deleted reply")
+		}
+		depth = parent.Depth + 1
+		if depth > u.replyConfig.MaxDepth {
+			return nil, fmt.Errorf("replies cannot be nested more than %d levels deep", u.replyConfig.MaxDepth)
+		}
+	}
+
+	contentHTML, err := infrastructure.RenderContent(contentFormat, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
+	// An anonymous reply does not record its author, only a tag that lets
+	// them edit and delete it
+	authorID := &userID
+	var ownerTag *string
+	if isAnonymous {
+		tag, err := u.ownerTagger.Tag(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to tag reply owner: %w", err)
+		}
+		authorID, ownerTag = nil, &tag
 	}
 
 	reply := &domain.Reply{
-		Content:     content,
-		PostID:      postID,
-		AuthorID:    authorID,
-		IsAnonymous: isAnonymous,
+		Content:       content,
+		ContentFormat: contentFormat,
+		ContentHTML:   contentHTML,
+		PostID:        postID,
+		ParentReplyID: parentReplyID,
+		Depth:         depth,
+		AuthorID:      authorID,
+		OwnerTag:      ownerTag,
+		IsAnonymous:   isAnonymous,
 	}
 
 	err = u.postRepo.CreateReply(reply)
@@ -266,23 +532,430 @@ func (u *PostUsecase) CreateReply(userID, postID int, content string, isAnonymou
 		return nil, fmt.Errorf("failed to create reply: %w", err)
 	}
 
+	reply.Mentions, err = u.setMentions(post, &reply.ID, content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set author info if not anonymous
 	if !isAnonymous {
 		reply.Author = user
 	}
 
-	slog.Info("Reply created successfully", "reply_id", reply.ID, "post_id", postID, "user_id", userID, "anonymous", isAnonymous)
+	// Notify the post author and the author of the reply being answered,
+	// without naming an anonymous replier
+	notification := domain.Notification{
+		Type:    domain.NotificationTypeReply,
+		ReplyID: &reply.ID,
+	}
+	if !isAnonymous {
+		notification.ActorID = &userID
+	}
+	recipients := []int{post.AuthorID}
+	if parent != nil && parent.AuthorID != nil && *parent.AuthorID != post.AuthorID {
+		recipients = append(recipients, *parent.AuthorID)
+	}
+	for _, recipientID := range recipients {
+		notification.UserID = recipientID
+		u.notifyAboutPost(post, userID, notification)
+	}
+
+	u.notifyMentions(post, &reply.ID, userID, isAnonymous)
+
+	if post.AuthorID != userID {
+		u.eventUsecase.Publish(post.AuthorID, domain.EventTypeReplyCreated, domain.EventData{
+			PostID:        &postID,
+			ReplyID:       &reply.ID,
+			ParentReplyID: parentReplyID,
+		})
+	}
+
+	slog.Info("Reply created successfully", "reply_id", reply.ID, "post_id", postID, "parent_reply_id", parentReplyID, "user_id", userID, "anonymous", isAnonymous)
+	return reply, nil
+}
+
+// GetReplies lists the replies to a post, or to one of its replies when
+// parentReplyID is set, to anyone who can see the post
+func (u *PostUsecase) GetReplies(postID int, parentReplyID *int, pageReq domain.PostPageRequest, userID *int) (*domain.ReplyPage, error) {
+	if _, err := u.getVisiblePost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	if parentReplyID != nil {
+		parent, err := u.postRepo.GetReplyByID(*parentReplyID)
+		if err != nil || parent.PostID != postID {
+			return nil, errors.New("parent reply not found")
+		}
+	}
+
+	page, err := u.postRepo.GetReplies(postID, parentReplyID, pageReq, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replies: %w", err)
+	}
+
+	return page, nil
+}
+
+// UpdateReply lets the author change a reply within the edit window
+func (u *PostUsecase) UpdateReply(userID, postID, replyID int, content string, contentFormat domain.ContentFormat) (*domain.Reply, error) {
+	reply, err := u.getReply(postID, replyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.isReplyAuthor(reply, userID) {
+		return nil, errors.New("you can only edit your own replies")
+	}
+
+	if time.Since(reply.CreatedAt) > u.replyConfig.EditWindow {
+		return nil, errors.New("replies can no longer be edited")
+	}
+
+	contentHTML, err := infrastructure.RenderContent(contentFormat, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
+	reply.Content = content
+	reply.ContentFormat = contentFormat
+	reply.ContentHTML = contentHTML
+
+	err = u.postRepo.UpdateReply(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reply: %w", err)
+	}
+
+	post, err := u.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	reply.Mentions, err = u.setMentions(post, &replyID, content)
+	if err != nil {
+		return nil, err
+	}
+	u.notifyMentions(post, &replyID, userID, reply.IsAnonymous)
+
+	slog.Info("Reply updated successfully", "reply_id", replyID, "post_id", postID, "user_id", userID)
+	return reply, nil
+}
+
+// DeleteReply leaves a tombstone in place of a reply. Authors can delete
+// their own replies and admins any reply.
+func (u *PostUsecase) DeleteReply(userID, postID, replyID int) error {
+	reply, err := u.getReply(postID, replyID)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.Role != domain.UserRoleAdmin && !u.isReplyAuthor(reply, userID) {
+		return errors.New("you can only delete your own replies")
+	}
+
+	err = u.postRepo.DeleteReply(replyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reply: %w", err)
+	}
+
+	err = u.postRepo.SetMentions(postID, &replyID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove mentions: %w", err)
+	}
+
+	slog.Info("Reply deleted successfully", "reply_id", replyID, "post_id", postID, "user_id", userID, "is_admin", user.Role == domain.UserRoleAdmin)
+	return nil
+}
+
+// isReplyAuthor reports whether userID wrote reply, named or anonymously
+func (u *PostUsecase) isReplyAuthor(reply *domain.Reply, userID int) bool {
+	if reply.AuthorID != nil {
+		return *reply.AuthorID == userID
+	}
+	return reply.OwnerTag != nil && u.ownerTagger.Matches(*reply.OwnerTag, userID)
+}
+
+// setMentions records the users mentioned in content, the post's own or
+// that of reply replyID when it is not nil, and returns them. Names that are
+// not active users stay plain text, and so do users outside the group of a
+// group post, so that a mention never tells them about it.
+func (u *PostUsecase) setMentions(post *domain.Post, replyID *int, content string) ([]domain.Mention, error) {
+	var mentions []domain.Mention
+	resolved := make(map[string]*domain.User)
+	for _, match := range findMentions(content) {
+		user, seen := resolved[match.Name]
+		if !seen {
+			if len(resolved) >= maxMentionsPerContent {
+				continue
+			}
+			var err error
+			user, err = u.resolveMention(match.Name, post.GroupID)
+			if err != nil {
+				return nil, err
+			}
+			resolved[match.Name] = user
+		}
+		if user != nil {
+			mentions = append(mentions, domain.Mention{
+				UserID:      user.ID,
+				DisplayName: user.DisplayName,
+				Start:       match.Start,
+				End:         match.End,
+			})
+		}
+	}
+
+	if err := u.postRepo.SetMentions(post.ID, replyID, mentions); err != nil {
+		return nil, fmt.Errorf("failed to record mentions: %w", err)
+	}
+	return mentions, nil
+}
+
+// resolveMention returns the user a mention refers to, or nil if there is
+// none that may be mentioned in the group
+func (u *PostUsecase) resolveMention(displayName string, groupID *int) (*domain.User, error) {
+	user, err := u.userRepo.GetByDisplayName(displayName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mention: %w", err)
+	}
+
+	if groupID != nil {
+		isMember, err := u.postRepo.IsGroupMember(*groupID, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check group membership: %w", err)
+		}
+		if !isMember {
+			return nil, nil
+		}
+	}
+	return user, nil
+}
+
+// notifyMentions notifies the users mentioned in the post's own content, or
+// in reply replyID when it is not nil, who were not notified yet. userID is
+// the author of the content, named unless they wrote it anonymously.
+func (u *PostUsecase) notifyMentions(post *domain.Post, replyID *int, userID int, anonymous bool) {
+	mentionedIDs, err := u.postRepo.TakeUnnotifiedMentions(post.ID, replyID)
+	if err != nil {
+		slog.Error("Failed to get mentions to notify", "post_id", post.ID, "reply_id", replyID, "error", err)
+		return
+	}
+
+	notification := domain.Notification{
+		Type:    domain.NotificationTypeMention,
+		ReplyID: replyID,
+	}
+	if !anonymous {
+		notification.ActorID = &userID
+	}
+	for _, mentionedID := range mentionedIDs {
+		notification.UserID = mentionedID
+		u.notifyAboutPost(post, userID, notification)
+	}
+}
+
+// getReply returns a reply of the post that has not been deleted
+func (u *PostUsecase) getReply(postID, replyID int) (*domain.Reply, error) {
+	reply, err := u.postRepo.GetReplyByID(replyID)
+	if err != nil || reply.PostID != postID || reply.IsDeleted {
+		return nil, errors.New("reply not found")
+	}
 	return reply, nil
 }
 
+// getVisiblePost returns a published post, checking group membership for
+// group posts
+func (u *PostUsecase) getVisiblePost(postID int, userID *int) (*domain.Post, error) {
+	post, err := u.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	// Only return approved posts that have been published
+	if !post.IsPublished() {
+		return nil, errors.New("post not available")
+	}
+
+	// If it's a group post, check if user is member of the group
+	if post.GroupID != nil {
+		if userID == nil {
+			return nil, errors.New("authentication required")
+		}
+		isMember, err := u.postRepo.IsGroupMember(*post.GroupID, *userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check group membership: %w", err)
+		}
+		if !isMember {
+			return nil, errors.New("you are not a member of this group")
+		}
+	}
+
+	return post, nil
+}
+
+// Revision functions
+func (u *PostUsecase) GetPostRevisions(userID, postID int) ([]domain.PostRevision, error) {
+	if _, err := u.getManagedPost(userID, postID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := u.revisionRepo.GetByPostID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (u *PostUsecase) DiffPostRevisions(userID, postID, fromRevision, toRevision int) (*domain.PostRevisionDiff, error) {
+	if _, err := u.getManagedPost(userID, postID); err != nil {
+		return nil, err
+	}
+
+	from, err := u.revisionRepo.GetByRevision(postID, fromRevision)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d not found", fromRevision)
+	}
+	to, err := u.revisionRepo.GetByRevision(postID, toRevision)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d not found", toRevision)
+	}
+
+	return &domain.PostRevisionDiff{
+		From:    from,
+		To:      to,
+		Title:   diffLines(from.Title, to.Title),
+		Content: diffLines(from.Content, to.Content),
+	}, nil
+}
+
+// RestorePostRevision saves an earlier revision as the post's current
+// content, recorded as a new revision. Authors can restore while the post is
+// still editable; admins can restore at any time without changing its status.
+func (u *PostUsecase) RestorePostRevision(userID, postID, revisionNumber int) (*domain.Post, error) {
+	post, err := u.getManagedPost(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Role != domain.UserRoleAdmin && !isEditable(post) {
+		return nil, errors.New("can only restore drafts or posts that are pending approval")
+	}
+
+	revision, err := u.revisionRepo.GetByRevision(postID, revisionNumber)
+	if err != nil {
+		return nil, errors.New("revision not found")
+	}
+
+	contentHTML, err := infrastructure.RenderContent(revision.ContentFormat, revision.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render content: %w", err)
+	}
+
+	post.Title = revision.Title
+	post.Content = revision.Content
+	post.ContentFormat = revision.ContentFormat
+	post.ContentHTML = contentHTML
+	post.ThumbnailURL = revision.ThumbnailURL
+
+	err = u.postRepo.Update(post)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore post: %w", err)
+	}
+
+	err = u.postRepo.SetPostCategories(postID, revision.CategoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore categories: %w", err)
+	}
+
+	err = u.postRepo.SetPostTags(postID, findHashtags(revision.Content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore tags: %w", err)
+	}
+
+	if _, err := u.setMentions(post, nil, revision.Content); err != nil {
+		return nil, err
+	}
+	if post.IsPublished() {
+		u.notifyMentions(post, nil, post.AuthorID, false)
+	}
+
+	restoredPost, err := u.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restored post: %w", err)
+	}
+
+	err = u.recordRevision(restoredPost, userID, &revision.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	slog.Info("Post revision restored successfully", "post_id", postID, "revision", revisionNumber, "user_id", userID)
+	return restoredPost, nil
+}
+
+// isEditable reports whether the author may still change the post
+func isEditable(post *domain.Post) bool {
+	return post.Status == domain.PostStatusDraft || post.Status == domain.PostStatusPending
+}
+
+// getManagedPost returns the post if userID is its author or an admin
+func (u *PostUsecase) getManagedPost(userID, postID int) (*domain.Post, error) {
+	post, err := u.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	if post.AuthorID == userID {
+		return post, nil
+	}
+
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Role != domain.UserRoleAdmin {
+		return nil, errors.New("only the author or an admin can manage revisions")
+	}
+	return post, nil
+}
+
+// recordRevision stores post, as just saved by editorID, as a new revision
+func (u *PostUsecase) recordRevision(post *domain.Post, editorID int, restoredFrom *int) error {
+	categoryIDs := make([]int, len(post.Categories))
+	for i, category := range post.Categories {
+		categoryIDs[i] = category.ID
+	}
+
+	return u.revisionRepo.Create(&domain.PostRevision{
+		PostID:        post.ID,
+		Title:         post.Title,
+		Content:       post.Content,
+		ContentFormat: post.ContentFormat,
+		ThumbnailURL:  post.ThumbnailURL,
+		CategoryIDs:   categoryIDs,
+		EditorID:      &editorID,
+		RestoredFrom:  restoredFrom,
+	})
+}
+
 // Admin functions
-func (u *PostUsecase) GetPostsForAdmin(page, limit int, status *domain.PostStatus) ([]*domain.Post, int, error) {
-	posts, total, err := u.postRepo.GetForAdmin(page, limit, status)
+func (u *PostUsecase) GetPostsForAdmin(pageReq domain.PostPageRequest, status *domain.PostStatus) (*domain.PostPage, error) {
+	page, err := u.postRepo.GetForAdmin(pageReq, status)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get posts for admin: %w", err)
+		return nil, fmt.Errorf("failed to get posts for admin: %w", err)
 	}
 
-	return posts, total, nil
+	return page, nil
 }
 
 func (u *PostUsecase) ApprovePost(postID int) error {
@@ -291,6 +964,10 @@ func (u *PostUsecase) ApprovePost(postID int) error {
 		return errors.New("post not found")
 	}
 
+	if post.Status == domain.PostStatusDraft {
+		return errors.New("drafts cannot be moderated")
+	}
+
 	if post.Status == domain.PostStatusApproved {
 		return errors.New("post is already approved")
 	}
@@ -300,6 +977,34 @@ func (u *PostUsecase) ApprovePost(postID int) error {
 		return fmt.Errorf("failed to approve post: %w", err)
 	}
 
+	// Scheduled posts are published later by PublishDuePosts
+	publishNow := post.PublishAt == nil || !post.PublishAt.After(time.Now())
+	if publishNow {
+		err = u.postRepo.MarkPublished(postID)
+		if err != nil {
+			return fmt.Errorf("failed to publish post: %w", err)
+		}
+	}
+
+	err = u.revisionRepo.MarkLatestApproved(postID)
+	if err != nil {
+		return fmt.Errorf("failed to mark approved revision: %w", err)
+	}
+
+	u.notificationUsecase.Notify(&domain.Notification{
+		UserID: post.AuthorID,
+		Type:   domain.NotificationTypePostApproved,
+		PostID: &postID,
+	})
+	u.eventUsecase.Publish(post.AuthorID, domain.EventTypePostApproved, domain.EventData{PostID: &postID})
+	// A post published before (and rejected since) was already announced
+	if publishNow && post.PublishedAt == nil {
+		u.publishGroupPost(post)
+	}
+	if publishNow {
+		u.notifyMentions(post, nil, post.AuthorID, false)
+	}
+
 	slog.Info("Post approved successfully", "post_id", postID)
 	return nil
 }
@@ -310,6 +1015,10 @@ func (u *PostUsecase) RejectPost(postID int) error {
 		return errors.New("post not found")
 	}
 
+	if post.Status == domain.PostStatusDraft {
+		return errors.New("drafts cannot be moderated")
+	}
+
 	if post.Status == domain.PostStatusRejected {
 		return errors.New("post is already rejected")
 	}
@@ -319,6 +1028,13 @@ func (u *PostUsecase) RejectPost(postID int) error {
 		return fmt.Errorf("failed to reject post: %w", err)
 	}
 
+	u.notificationUsecase.Notify(&domain.Notification{
+		UserID: post.AuthorID,
+		Type:   domain.NotificationTypePostRejected,
+		PostID: &postID,
+	})
+	u.eventUsecase.Publish(post.AuthorID, domain.EventTypePostRejected, domain.EventData{PostID: &postID})
+
 	slog.Info("Post rejected successfully", "post_id", postID)
 	return nil
 }
@@ -356,7 +1072,7 @@ func (u *PostUsecase) ToggleLike(postID, userID int) error {
 		return errors.New("post not found")
 	}
 	
-	if post.Status != domain.PostStatusApproved {
+	if !post.IsPublished() {
 		return errors.New("can only like approved posts")
 	}
 	
@@ -371,15 +1087,149 @@ func (u *PostUsecase) ToggleLike(postID, userID int) error {
 		}
 	}
 	
-	err = u.postRepo.ToggleLike(postID, userID)
+	liked, err := u.postRepo.ToggleLike(postID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to toggle like: %w", err)
 	}
+
+	if liked {
+		u.notifyReaction(post, nil, userID, domain.ReactionLike)
+	}
 	
 	slog.Info("Like toggled successfully", "post_id", postID, "user_id", userID)
 	return nil
 }
 
+// Reaction functions
+func (u *PostUsecase) AddPostReaction(userID, postID int, emoji string) error {
+	if !u.isAllowedReaction(emoji) {
+		return ErrUnsupportedReaction
+	}
+
+	post, err := u.getVisiblePost(postID, &userID)
+	if err != nil {
+		return err
+	}
+
+	added, err := u.postRepo.AddPostReaction(postID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	if added {
+		u.notifyReaction(post, nil, userID, emoji)
+	}
+
+	slog.Info("Reaction added successfully", "post_id", postID, "user_id", userID, "emoji", emoji)
+	return nil
+}
+
+// RemovePostReaction also accepts reactions that are no longer allowed so
+// that users can take back ones added before the set changed
+func (u *PostUsecase) RemovePostReaction(userID, postID int, emoji string) error {
+	if _, err := u.getVisiblePost(postID, &userID); err != nil {
+		return err
+	}
+
+	if err := u.postRepo.RemovePostReaction(postID, userID, emoji); err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	slog.Info("Reaction removed successfully", "post_id", postID, "user_id", userID, "emoji", emoji)
+	return nil
+}
+
+func (u *PostUsecase) AddReplyReaction(userID, postID, replyID int, emoji string) error {
+	if !u.isAllowedReaction(emoji) {
+		return ErrUnsupportedReaction
+	}
+
+	post, err := u.getVisiblePost(postID, &userID)
+	if err != nil {
+		return err
+	}
+	reply, err := u.getReply(postID, replyID)
+	if err != nil {
+		return err
+	}
+
+	added, err := u.postRepo.AddReplyReaction(replyID, userID, emoji)
+	if err != nil {
+		return fmt.Errorf("failed to add reaction: %w", err)
+	}
+
+	if added {
+		u.notifyReaction(post, reply, userID, emoji)
+	}
+
+	slog.Info("Reaction added successfully", "post_id", postID, "reply_id", replyID, "user_id", userID, "emoji", emoji)
+	return nil
+}
+
+func (u *PostUsecase) RemoveReplyReaction(userID, postID, replyID int, emoji string) error {
+	if _, err := u.getVisiblePost(postID, &userID); err != nil {
+		return err
+	}
+	if _, err := u.getReply(postID, replyID); err != nil {
+		return err
+	}
+
+	if err := u.postRepo.RemoveReplyReaction(replyID, userID, emoji); err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	slog.Info("Reaction removed successfully", "post_id", postID, "reply_id", replyID, "user_id", userID, "emoji", emoji)
+	return nil
+}
+
+// notifyReaction tells the author of post, or of reply if it is set, that
+// userID reacted to it
+func (u *PostUsecase) notifyReaction(post *domain.Post, reply *domain.Reply, userID int, emoji string) {
+	notification := domain.Notification{
+		UserID:  post.AuthorID,
+		Type:    domain.NotificationTypeReaction,
+		ActorID: &userID,
+		Emoji:   &emoji,
+	}
+	if reply != nil {
+		if reply.AuthorID == nil {
+			return
+		}
+		notification.UserID = *reply.AuthorID
+		notification.ReplyID = &reply.ID
+	}
+	u.notifyAboutPost(post, userID, notification)
+}
+
+// notifyAboutPost sends a notification about something userID did on post.
+// Nobody is notified of their own actions, and for group posts only the
+// author and current members are, so that people who left the group do not
+// learn about its posts.
+func (u *PostUsecase) notifyAboutPost(post *domain.Post, userID int, notification domain.Notification) {
+	if notification.UserID == userID {
+		return
+	}
+
+	if post.GroupID != nil && notification.UserID != post.AuthorID {
+		isMember, err := u.postRepo.IsGroupMember(*post.GroupID, notification.UserID)
+		if err != nil || !isMember {
+			return
+		}
+	}
+
+	notification.PostID = &post.ID
+	u.notificationUsecase.Notify(&notification)
+}
+
+func (u *PostUsecase) isAllowedReaction(emoji string) bool {
+	for _, allowed := range u.reactionConfig.Allowed {
+		if emoji == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 // Group functions
 func (u *PostUsecase) CreateGroup(userID int, name, description string) (*domain.Group, error) {
 	// Check if user has reached the limit of 3 groups
@@ -439,26 +1289,19 @@ func (u *PostUsecase) AddGroupMember(groupID, ownerID, userID int) error {
 		return fmt.Errorf("failed to add group member: %w", err)
 	}
 	
+	u.notifyGroupAdded(groupID, ownerID, userID)
+	
 	slog.Info("Group member added successfully", "group_id", groupID, "user_id", userID, "owner_id", ownerID)
 	return nil
 }
 
-func (u *PostUsecase) GetGroupPosts(groupID, userID, page, limit int) ([]*domain.Post, int, error) {
-	posts, total, err := u.postRepo.GetGroupPosts(groupID, userID, page, limit)
+func (u *PostUsecase) GetGroupPosts(groupID, userID int, pageReq domain.PostPageRequest) (*domain.PostPage, error) {
+	page, err := u.postRepo.GetGroupPosts(groupID, userID, pageReq)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get group posts: %w", err)
-	}
-	
-	// Set like status
-	for _, post := range posts {
-		isLiked, err := u.postRepo.IsLikedByUser(post.ID, userID)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to check like status: %w", err)
-		}
-		post.IsLiked = isLiked
+		return nil, fmt.Errorf("failed to get group posts: %w", err)
 	}
 	
-	return posts, total, nil
+	return page, nil
 }
 
 func (u *PostUsecase) UpdateGroup(groupID, ownerID int, name, description string) (*domain.Group, error) {
@@ -512,12 +1355,23 @@ func (u *PostUsecase) DeleteGroup(groupID, ownerID int) error {
 		return errors.New("only group owner can delete group")
 	}
 	
+	members, err := u.postRepo.GetGroupMembers(groupID)
+	if err != nil {
+		return fmt.Errorf("failed to get group members: %w", err)
+	}
+	
 	// Delete group (this will cascade delete members and posts)
 	err = u.postRepo.DeleteGroup(groupID)
 	if err != nil {
 		return fmt.Errorf("failed to delete group: %w", err)
 	}
 	
+	for _, member := range members {
+		if member.ID != ownerID {
+			u.eventUsecase.Publish(member.ID, domain.EventTypeGroupMemberRemoved, domain.EventData{GroupID: &groupID})
+		}
+	}
+	
 	slog.Info("Group deleted successfully", "group_id", groupID, "owner_id", ownerID)
 	return nil
 }
@@ -562,10 +1416,34 @@ func (u *PostUsecase) AddGroupMemberByDisplayName(groupID, ownerID int, displayN
 		return fmt.Errorf("failed to add group member: %w", err)
 	}
 	
+	u.notifyGroupAdded(groupID, ownerID, user.ID)
+	
 	slog.Info("Group member added successfully", "group_id", groupID, "user_id", user.ID, "display_name", displayName, "owner_id", ownerID)
 	return nil
 }
 
+func (u *PostUsecase) notifyGroupAdded(groupID, ownerID, userID int) {
+	u.notificationUsecase.Notify(&domain.Notification{
+		UserID:  userID,
+		Type:    domain.NotificationTypeGroupAdded,
+		ActorID: &ownerID,
+		GroupID: &groupID,
+	})
+	u.eventUsecase.Publish(userID, domain.EventTypeGroupMemberAdded, domain.EventData{GroupID: &groupID})
+}
+
+// publishGroupPost tells the other members of a group that a post in it
+// was published
+func (u *PostUsecase) publishGroupPost(post *domain.Post) {
+	if post.GroupID == nil {
+		return
+	}
+	u.eventUsecase.PublishToGroup(*post.GroupID, post.AuthorID, domain.EventTypeGroupPostPublished, domain.EventData{
+		PostID:  &post.ID,
+		GroupID: post.GroupID,
+	})
+}
+
 func (u *PostUsecase) SearchUsersByDisplayName(query string) ([]domain.User, error) {
 	if len(query) < 2 {
 		return nil, errors.New("search query must be at least 2 characters")
@@ -654,6 +1532,8 @@ func (u *PostUsecase) RemoveGroupMember(groupID, ownerID, memberID int) error {
 		return fmt.Errorf("failed to remove group member: %w", err)
 	}
 	
+	u.eventUsecase.Publish(memberID, domain.EventTypeGroupMemberRemoved, domain.EventData{GroupID: &groupID})
+	
 	slog.Info("Group member removed successfully", "group_id", groupID, "member_id", memberID, "owner_id", ownerID)
 	return nil
 }
@@ -675,9 +1555,13 @@ func (u *PostUsecase) LeaveGroup(groupID, userID int) error {
 		return fmt.Errorf("failed to get user groups: %w", err)
 	}
 	
+	ownerID := 0
 	for _, group := range groups {
-		if group.ID == groupID && group.OwnerID == userID {
-			return errors.New("group owner cannot leave group, delete group instead")
+		if group.ID == groupID {
+			if group.OwnerID == userID {
+				return errors.New("group owner cannot leave group, delete group instead")
+			}
+			ownerID = group.OwnerID
 		}
 	}
 	
@@ -686,6 +1570,10 @@ func (u *PostUsecase) LeaveGroup(groupID, userID int) error {
 		return fmt.Errorf("failed to leave group: %w", err)
 	}
 	
+	if ownerID != 0 {
+		u.eventUsecase.Publish(ownerID, domain.EventTypeGroupMemberLeft, domain.EventData{GroupID: &groupID, UserID: &userID})
+	}
+	
 	slog.Info("User left group successfully", "group_id", groupID, "user_id", userID)
 	return nil
 }
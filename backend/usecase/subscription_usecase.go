@@ -73,6 +73,10 @@ func (u *SubscriptionUsecase) CreateCheckoutSession(userID int) (string, error)
 		return "", errors.New("user not found")
 	}
 
+	if !user.EmailVerified {
+		return "", ErrEmailNotVerified
+	}
+
 	// Mock mode: return a mock URL and set user as active
 	if u.mockMode {
 		slog.Info("Mock mode: Creating checkout session", "user_id", userID)
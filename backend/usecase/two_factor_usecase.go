@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"posting-app/domain"
+	"posting-app/infrastructure"
+	"posting-app/repository"
+)
+
+const (
+	totpIssuer        = "Posting App"
+	recoveryCodeCount = 10
+)
+
+type TwoFactorUsecase struct {
+	twoFactorRepo  *repository.TwoFactorRepository
+	userRepo       *repository.UserRepository
+	loginThrottler *LoginThrottler
+	secretBox      *infrastructure.SecretBox
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+func NewTwoFactorUsecase(twoFactorRepo *repository.TwoFactorRepository, userRepo *repository.UserRepository, loginThrottler *LoginThrottler, secretBox *infrastructure.SecretBox) *TwoFactorUsecase {
+	return &TwoFactorUsecase{
+		twoFactorRepo:  twoFactorRepo,
+		userRepo:       userRepo,
+		loginThrottler: loginThrottler,
+		secretBox:      secretBox,
+	}
+}
+
+func (u *TwoFactorUsecase) GetStatus(userID int) (*TwoFactorStatus, error) {
+	credential, err := u.twoFactorRepo.GetByUserID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &TwoFactorStatus{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor status: %w", err)
+	}
+
+	if !credential.Enabled {
+		return &TwoFactorStatus{}, nil
+	}
+
+	remaining, err := u.twoFactorRepo.CountRemainingRecoveryCodes(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return &TwoFactorStatus{
+		Enabled:                true,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+func (u *TwoFactorUsecase) IsEnabled(userID int) (bool, error) {
+	credential, err := u.twoFactorRepo.GetByUserID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get two-factor status: %w", err)
+	}
+	return credential.Enabled, nil
+}
+
+// Enroll generates a new secret. It only becomes active once Confirm is called
+// with a code produced from it.
+func (u *TwoFactorUsecase) Enroll(userID int) (*TwoFactorEnrollment, error) {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	enabled, err := u.IsEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := infrastructure.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := u.secretBox.Seal(secret, secretContext(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt two-factor secret: %w", err)
+	}
+
+	err = u.twoFactorRepo.SavePending(&domain.TwoFactorCredential{
+		UserID: userID,
+		Secret: sealed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save two-factor secret: %w", err)
+	}
+
+	slog.Info("Two-factor enrollment started", "user_id", userID)
+	return &TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: infrastructure.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm activates a pending enrollment and returns the recovery codes.
+// The codes are only ever shown here.
+func (u *TwoFactorUsecase) Confirm(userID int, code string) ([]string, error) {
+	credential, err := u.getCredential(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("two-factor enrollment not started")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := infrastructure.ValidateTOTP(credential.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	err = u.twoFactorRepo.Enable(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	_, err = u.twoFactorRepo.UpdateLastUsedStep(userID, step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+
+	codes, err := u.generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Two-factor authentication enabled", "user_id", userID)
+	return codes, nil
+}
+
+func (u *TwoFactorUsecase) Disable(userID int, password, code string) error {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	err = u.loginThrottler.Check(user.Email, "")
+	if err != nil {
+		return err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		u.recordFailure(user)
+		return errors.New("password is incorrect")
+	}
+
+	err = u.verifyThrottledCode(user, code)
+	if err != nil {
+		return err
+	}
+
+	err = u.twoFactorRepo.Delete(userID)
+	if err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	slog.Info("Two-factor authentication disabled", "user_id", userID)
+	return nil
+}
+
+func (u *TwoFactorUsecase) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	user, err := u.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	err = u.loginThrottler.Check(user.Email, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.verifyThrottledCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := u.generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Recovery codes regenerated", "user_id", userID)
+	return codes, nil
+}
+
+// verifyThrottledCode checks a code for an action of a signed-in user. A
+// stolen session must not allow unlimited guesses, so failures count against
+// the same per-account limit as failed logins and a success clears it.
+func (u *TwoFactorUsecase) verifyThrottledCode(user *domain.User, code string) error {
+	ok, err := u.VerifyCode(user.ID, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		slog.Warn("Invalid two-factor code", "user_id", user.ID)
+		u.recordFailure(user)
+		return errors.New("invalid two-factor code")
+	}
+
+	return u.loginThrottler.Reset(user.Email)
+}
+
+// recordFailure counts a failed password or code check against the account.
+// Errors are only logged, as for failed logins.
+func (u *TwoFactorUsecase) recordFailure(user *domain.User) {
+	if _, err := u.loginThrottler.RecordFailure(user.Email, ""); err != nil {
+		slog.Error("Failed to record two-factor failure", "user_id", user.ID, "error", err)
+	}
+}
+
+// VerifyCode accepts either a current TOTP code or an unused recovery code.
+// Both are single use.
+func (u *TwoFactorUsecase) VerifyCode(userID int, code string) (bool, error) {
+	credential, err := u.getCredential(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !credential.Enabled {
+		return false, nil
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := infrastructure.ValidateTOTP(credential.Secret, code, time.Now()); ok {
+		used, err := u.twoFactorRepo.UpdateLastUsedStep(userID, step)
+		if err != nil {
+			return false, fmt.Errorf("failed to record two-factor code: %w", err)
+		}
+		return used, nil
+	}
+
+	used, err := u.twoFactorRepo.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if used {
+		slog.Info("Recovery code used", "user_id", userID)
+	}
+
+	return used, nil
+}
+
+// getCredential returns a user's credential with its secret decrypted. A
+// secret stored in plaintext before encryption was introduced is encrypted
+// on the way. It returns sql.ErrNoRows if the user has no credential.
+func (u *TwoFactorUsecase) getCredential(userID int) (*domain.TwoFactorCredential, error) {
+	credential, err := u.twoFactorRepo.GetByUserID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor credential: %w", err)
+	}
+
+	if !infrastructure.IsSealed(credential.Secret) {
+		sealed, err := u.secretBox.Seal(credential.Secret, secretContext(userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt two-factor secret: %w", err)
+		}
+		if err := u.twoFactorRepo.UpdateSecret(userID, credential.Secret, sealed); err != nil {
+			return nil, fmt.Errorf("failed to encrypt two-factor secret: %w", err)
+		}
+		return credential, nil
+	}
+
+	credential.Secret, err = u.secretBox.Open(credential.Secret, secretContext(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt two-factor secret: %w", err)
+	}
+	return credential, nil
+}
+
+// secretContext binds an encrypted secret to its user
+func secretContext(userID int) string {
+	return fmt.Sprintf("two_factor_credentials:%d", userID)
+}
+
+func (u *TwoFactorUsecase) generateRecoveryCodes(userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := generateRandomToken(5)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	err := u.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes with or without the dash
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
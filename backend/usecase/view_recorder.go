@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"posting-app/domain"
+	"posting-app/repository"
+)
+
+// ViewConfig controls how post views are buffered. Views are written every
+// FlushInterval, or as soon as MaxPending distinct views are waiting. New
+// views are dropped while MaxBuffered are waiting, e.g. while the database
+// is slow or down.
+type ViewConfig struct {
+	FlushInterval time.Duration `envconfig:"VIEW_FLUSH_INTERVAL" default:"10s"`
+	MaxPending    int           `envconfig:"VIEW_MAX_PENDING" default:"1000"`
+	MaxBuffered   int           `envconfig:"VIEW_MAX_BUFFERED" default:"10000"`
+}
+
+// ViewRecorder collects post views in memory and writes them in batches so
+// that reading a post does not wait for a write. Repeated views by a user on
+// the same day are merged before they reach the database. Views still
+// pending when the process stops without Close are lost.
+type ViewRecorder struct {
+	viewRepo *repository.PostViewRepository
+	config   ViewConfig
+
+	mu      sync.Mutex
+	pending map[domain.PostView]struct{}
+	dropped int
+
+	full    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func NewViewRecorder(viewRepo *repository.PostViewRepository, config ViewConfig) *ViewRecorder {
+	r := &ViewRecorder{
+		viewRepo: viewRepo,
+		config:   config,
+		pending:  make(map[domain.PostView]struct{}),
+		full:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record counts a view of postID by userID for today (UTC)
+func (r *ViewRecorder) Record(postID, userID int) {
+	view := domain.PostView{
+		PostID:   postID,
+		UserID:   userID,
+		ViewedOn: time.Now().UTC().Truncate(24 * time.Hour),
+	}
+
+	r.mu.Lock()
+	if _, ok := r.pending[view]; !ok && len(r.pending) >= r.config.MaxBuffered {
+		r.dropped++
+	} else {
+		r.pending[view] = struct{}{}
+	}
+	full := len(r.pending) >= r.config.MaxPending
+	r.mu.Unlock()
+
+	if full {
+		select {
+		case r.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close writes the pending views and stops the recorder
+func (r *ViewRecorder) Close() {
+	close(r.done)
+	<-r.stopped
+}
+
+func (r *ViewRecorder) run() {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.flush()
+		case <-r.full:
+			r.flush()
+		case <-r.done:
+			r.flush()
+			return
+		}
+	}
+}
+
+// flush writes the pending views. A batch that fails to be written is
+// dropped rather than retried so that a database outage cannot make the
+// buffer grow without bound.
+func (r *ViewRecorder) flush() {
+	r.mu.Lock()
+	if r.dropped > 0 {
+		slog.Error("Dropped post views because the buffer was full", "count", r.dropped, "max_buffered", r.config.MaxBuffered)
+		r.dropped = 0
+	}
+	if len(r.pending) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	pending := r.pending
+	r.pending = make(map[domain.PostView]struct{})
+	r.mu.Unlock()
+
+	views := make([]domain.PostView, 0, len(pending))
+	for view := range pending {
+		views = append(views, view)
+	}
+
+	if err := r.viewRepo.RecordViews(views); err != nil {
+		slog.Error("Failed to record post views", "count", len(views), "error", err)
+	}
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"posting-app/repository"
+)
+
+// downConnector opens connections to a database that fails every statement
+type downConnector struct{}
+
+func (downConnector) Connect(context.Context) (driver.Conn, error) {
+	return downConn{}, nil
+}
+
+func (downConnector) Driver() driver.Driver {
+	return nil
+}
+
+type downConn struct{}
+
+func (downConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("database is down")
+}
+
+func (downConn) Close() error {
+	return nil
+}
+
+func (downConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("database is down")
+}
+
+func TestViewRecorderDropsViewsBeyondMaxBuffered(t *testing.T) {
+	db := sql.OpenDB(downConnector{})
+	t.Cleanup(func() { db.Close() })
+
+	// No flushes until Close, and that one fails: the views stay buffered as
+	// if the database were down
+	r := NewViewRecorder(repository.NewPostViewRepository(db), ViewConfig{FlushInterval: time.Hour, MaxPending: 100, MaxBuffered: 10})
+	t.Cleanup(r.Close)
+
+	for postID := 1; postID <= 15; postID++ {
+		r.Record(postID, 1)
+	}
+	// A view that is already buffered is still merged
+	r.Record(1, 1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) != 10 {
+		t.Errorf("expected 10 buffered views, got %d", len(r.pending))
+	}
+	if r.dropped != 5 {
+		t.Errorf("expected 5 dropped views, got %d", r.dropped)
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"database/sql"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -11,7 +14,19 @@ import (
 	"posting-app/usecase"
 )
 
+// Jobs are selected with -job. The publisher is meant to run every minute or
+// so; the subscription sync, event pruning and cleanup once a day.
+const (
+	jobSubscriptionSync = "subscription-sync"
+	jobPublishPosts     = "publish-posts"
+	jobPruneEvents      = "prune-events"
+	jobCleanup          = "cleanup"
+)
+
 func main() {
+	job := flag.String("job", jobSubscriptionSync, "job to run: "+jobSubscriptionSync+", "+jobPublishPosts+", "+jobPruneEvents+" or "+jobCleanup)
+	flag.Parse()
+
 	// Setup logging
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
@@ -32,6 +47,27 @@ func main() {
 	}
 	defer db.Close()
 
+	switch *job {
+	case jobSubscriptionSync:
+		err = runSubscriptionSync(db, config)
+	case jobPublishPosts:
+		err = runPublishPosts(db, config)
+	case jobPruneEvents:
+		err = runPruneEvents(db, config)
+	case jobCleanup:
+		err = runCleanup(db)
+	default:
+		slog.Error("Unknown job", "job", *job)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		slog.Error("Batch job failed", "job", *job, "error", err)
+		os.Exit(1)
+	}
+}
+
+func runSubscriptionSync(db *sql.DB, config di.Config) error {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	subscriptionRepo := repository.NewSubscriptionRepository(db)
@@ -44,15 +80,81 @@ func main() {
 		config.StripePriceID,
 		config.StripeWebhookSecret,
 		config.BaseURL,
+		config.StripeMockMode,
 	)
 
 	// Run subscription status sync
 	slog.Info("Starting subscription status sync...")
-	err = subscriptionUsecase.SyncSubscriptionStatuses()
+	err := subscriptionUsecase.SyncSubscriptionStatuses()
 	if err != nil {
-		slog.Error("Failed to sync subscription statuses", "error", err)
-		os.Exit(1)
+		return err
 	}
 
 	slog.Info("Subscription status sync completed successfully")
-}
\ No newline at end of file
+	return nil
+}
+
+func runPublishPosts(db *sql.DB, config di.Config) error {
+	// Initialize repositories
+	postRepo := repository.NewPostRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	postRevisionRepo := repository.NewPostRevisionRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	eventRepo := repository.NewEventRepository(db)
+
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo)
+	// Events are only written here; the servers' listeners deliver them
+	eventUsecase := usecase.NewEventUsecase(eventRepo, nil, config.Event)
+	postUsecase := usecase.NewPostUsecase(postRepo, userRepo, postRevisionRepo, notificationUsecase, eventUsecase, nil, nil, config.Reply, config.Reaction, config.Tag)
+
+	// Release scheduled posts whose publish time has passed
+	slog.Info("Starting scheduled post publishing...")
+	published, err := postUsecase.PublishDuePosts()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Scheduled post publishing completed successfully", "published", published)
+	return nil
+}
+
+func runPruneEvents(db *sql.DB, config di.Config) error {
+	eventRepo := repository.NewEventRepository(db)
+	eventUsecase := usecase.NewEventUsecase(eventRepo, nil, config.Event)
+
+	// Remove events too old for clients to resume from
+	slog.Info("Starting event pruning...")
+	pruned, err := eventUsecase.PruneEvents()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Event pruning completed successfully", "pruned", pruned)
+	return nil
+}
+
+// runCleanup removes expired rows that nothing reads any more, table by
+// table, so that they do not grow with every login
+func runCleanup(db *sql.DB) error {
+	cleanups := []struct {
+		table string
+		run   func() error
+	}{
+		{"refresh_tokens", repository.NewRefreshTokenRepository(db).DeleteExpired},
+		{"revoked_tokens", repository.NewRevokedTokenRepository(db).DeleteExpired},
+		{"email_verifications", repository.NewEmailVerificationRepository(db).DeleteExpired},
+		{"account_unlocks", repository.NewAccountUnlockRepository(db).DeleteExpired},
+		{"oidc_login_states", repository.NewIdentityRepository(db).DeleteExpiredLoginStates},
+	}
+
+	slog.Info("Starting cleanup of expired rows...")
+	for _, cleanup := range cleanups {
+		if err := cleanup.run(); err != nil {
+			return fmt.Errorf("failed to clean up %s: %w", cleanup.table, err)
+		}
+		slog.Info("Expired rows removed", "table", cleanup.table)
+	}
+
+	slog.Info("Cleanup completed successfully")
+	return nil
+}
@@ -446,42 +446,9 @@ export const AdminDashboard: React.FC = () => {
                   {selectedPost.content}
                 </div>
               </div>
-              {selectedPost.replies && selectedPost.replies.length > 0 && (
-                <div>
-                  <strong>Replies ({selectedPost.replies.length}):</strong>
-                  <div style={{ marginTop: '0.5rem' }}>
-                    {selectedPost.replies.map((reply) => (
-                      <div
-                        key={reply.id}
-                        style={{
-                          padding: '0.5rem',
-                          backgroundColor: '#f3f4f6',
-                          borderRadius: '4px',
-                          marginBottom: '0.5rem',
-                          fontSize: '0.875rem',
-                        }}
-                      >
-                        <div
-                          style={{ fontWeight: '500', marginBottom: '0.25rem' }}
-                        >
-                          {reply.is_anonymous
-                            ? 'Anonymous'
-                            : reply.author?.display_name || 'Unknown'}{' '}
-                          • {new Date(reply.created_at).toLocaleDateString()}
-                        </div>
-                        <div
-                          style={{
-                            whiteSpace: 'pre-wrap',
-                            wordBreak: 'break-word',
-                          }}
-                        >
-                          {reply.content}
-                        </div>
-                      </div>
-                    ))}
-                  </div>
-                </div>
-              )}
+              <div>
+                <strong>Replies:</strong> {selectedPost.replies_count || 0}
+              </div>
             </div>
           )}
         </DialogContent>
@@ -383,7 +383,7 @@ export const Home: React.FC = () => {
                       )}
                     </div>
                     <div style={{ color: '#6b7280', fontSize: '0.875rem' }}>
-                      {post.replies?.length || 0} replies
+                      {post.replies_count || 0} replies
                     </div>
                   </div>
                 </div>
@@ -1,13 +1,15 @@
 import React, { useState } from 'react';
 import { Link, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
-import { authApi } from '../utils/api';
+import { authApi, isTwoFactorChallenge } from '../utils/api';
 
 export const Login: React.FC = () => {
   const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
+  const [mfaToken, setMfaToken] = useState('');
+  const [code, setCode] = useState('');
 
   const { login } = useAuth();
   const navigate = useNavigate();
@@ -15,7 +17,7 @@ export const Login: React.FC = () => {
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
 
-    if (!email || !password) {
+    if (mfaToken ? !code : !email || !password) {
       setError('Please fill in all fields');
       return;
     }
@@ -24,7 +26,18 @@ export const Login: React.FC = () => {
       setLoading(true);
       setError('');
 
+      if (mfaToken) {
+        const response = await authApi.verifyTwoFactor(mfaToken, code);
+        login(response.access_token, response.user);
+        navigate('/');
+        return;
+      }
+
       const response = await authApi.login(email, password);
+      if (isTwoFactorChallenge(response)) {
+        setMfaToken(response.mfa_token);
+        return;
+      }
       login(response.access_token, response.user);
       navigate('/');
     } catch (err: any) {
@@ -98,67 +111,103 @@ export const Login: React.FC = () => {
             </div>
           )}
 
-          <div>
-            <label
-              htmlFor="email"
-              style={{
-                display: 'block',
-                fontSize: '0.875rem',
-                fontWeight: '500',
-                color: '#374151',
-                marginBottom: '0.25rem',
-              }}
-            >
-              メールアドレス
-            </label>
-            <input
-              id="email"
-              type="email"
-              value={email}
-              onChange={(e) => setEmail(e.target.value)}
-              required
-              style={{
-                width: '100%',
-                padding: '0.75rem',
-                border: '1px solid #d1d5db',
-                borderRadius: '0.375rem',
-                fontSize: '0.875rem',
-                boxSizing: 'border-box',
-              }}
-              placeholder="メールアドレスを入力してください"
-            />
-          </div>
-
-          <div>
-            <label
-              htmlFor="password"
-              style={{
-                display: 'block',
-                fontSize: '0.875rem',
-                fontWeight: '500',
-                color: '#374151',
-                marginBottom: '0.25rem',
-              }}
-            >
-              パスワード
-            </label>
-            <input
-              id="password"
-              type="password"
-              value={password}
-              onChange={(e) => setPassword(e.target.value)}
-              required
-              style={{
-                width: '100%',
-                padding: '0.75rem',
-                border: '1px solid #d1d5db',
-                borderRadius: '0.375rem',
-                fontSize: '0.875rem',
-                boxSizing: 'border-box',
-              }}
-              placeholder="パスワードを入力してください"
-            />
-          </div>
+          {mfaToken ? (
+            <div>
+              <label
+                htmlFor="code"
+                style={{
+                  display: 'block',
+                  fontSize: '0.875rem',
+                  fontWeight: '500',
+                  color: '#374151',
+                  marginBottom: '0.25rem',
+                }}
+              >
+                認証コード
+              </label>
+              <input
+                id="code"
+                type="text"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                required
+                style={{
+                  width: '100%',
+                  padding: '0.75rem',
+                  border: '1px solid #d1d5db',
+                  borderRadius: '0.375rem',
+                  fontSize: '0.875rem',
+                  boxSizing: 'border-box',
+                }}
+                placeholder="認証アプリのコードまたはリカバリーコード"
+              />
+            </div>
+          ) : (
+            <>
+              <div>
+                <label
+                  htmlFor="email"
+                  style={{
+                    display: 'block',
+                    fontSize: '0.875rem',
+                    fontWeight: '500',
+                    color: '#374151',
+                    marginBottom: '0.25rem',
+                  }}
+                >
+                  メールアドレス
+                </label>
+                <input
+                  id="email"
+                  type="email"
+                  value={email}
+                  onChange={(e) => setEmail(e.target.value)}
+                  required
+                  style={{
+                    width: '100%',
+                    padding: '0.75rem',
+                    border: '1px solid #d1d5db',
+                    borderRadius: '0.375rem',
+                    fontSize: '0.875rem',
+                    boxSizing: 'border-box',
+                  }}
+                  placeholder="メールアドレスを入力してください"
+                />
+              </div>
+  
+              <div>
+                <label
+                  htmlFor="password"
+                  style={{
+                    display: 'block',
+                    fontSize: '0.875rem',
+                    fontWeight: '500',
+                    color: '#374151',
+                    marginBottom: '0.25rem',
+                  }}
+                >
+                  パスワード
+                </label>
+                <input
+                  id="password"
+                  type="password"
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  required
+                  style={{
+                    width: '100%',
+                    padding: '0.75rem',
+                    border: '1px solid #d1d5db',
+                    borderRadius: '0.375rem',
+                    fontSize: '0.875rem',
+                    boxSizing: 'border-box',
+                  }}
+                  placeholder="パスワードを入力してください"
+                />
+              </div>
+            </>
+          )}
 
           <div style={{ display: 'flex', justifyContent: 'flex-end' }}>
             <Link
@@ -1,7 +1,7 @@
 import React, { useState, useEffect, useCallback } from 'react';
 import { Link } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
-import { userApi, authApi } from '../utils/api';
+import { userApi, authApi, twoFactorApi } from '../utils/api';
 import { Post } from '../types';
 
 export const MyPage: React.FC = () => {
@@ -23,6 +23,15 @@ export const MyPage: React.FC = () => {
   const [passwordLoading, setPasswordLoading] = useState(false);
   const [passwordError, setPasswordError] = useState('');
   const [passwordSuccess, setPasswordSuccess] = useState('');
+  const [twoFactorEnabled, setTwoFactorEnabled] = useState(false);
+  const [enrollment, setEnrollment] = useState<{
+    secret: string;
+    otpauth_uri: string;
+  } | null>(null);
+  const [twoFactorCode, setTwoFactorCode] = useState('');
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
+  const [twoFactorLoading, setTwoFactorLoading] = useState(false);
+  const [twoFactorError, setTwoFactorError] = useState('');
 
   const limit = 10;
   const { user, updateUser } = useAuth();
@@ -62,6 +71,48 @@ export const MyPage: React.FC = () => {
     }
   }, [page, user, fetchPosts]);
 
+  useEffect(() => {
+    twoFactorApi
+      .getStatus()
+      .then((status) => setTwoFactorEnabled(status.enabled))
+      .catch((err) => console.error('Error fetching two-factor status:', err));
+  }, []);
+
+  const handleTwoFactorEnroll = async () => {
+    try {
+      setTwoFactorLoading(true);
+      setTwoFactorError('');
+      setEnrollment(await twoFactorApi.enroll());
+    } catch (err: any) {
+      setTwoFactorError(
+        err.response?.data?.message || 'Failed to start two-factor setup'
+      );
+    } finally {
+      setTwoFactorLoading(false);
+    }
+  };
+
+  const handleTwoFactorConfirm = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    try {
+      setTwoFactorLoading(true);
+      setTwoFactorError('');
+
+      const response = await twoFactorApi.confirm(twoFactorCode);
+      setRecoveryCodes(response.recovery_codes);
+      setTwoFactorEnabled(true);
+      setEnrollment(null);
+      setTwoFactorCode('');
+    } catch (err: any) {
+      setTwoFactorError(
+        err.response?.data?.message || 'Failed to confirm two-factor code'
+      );
+    } finally {
+      setTwoFactorLoading(false);
+    }
+  };
+
   const handleProfileUpdate = async (e: React.FormEvent) => {
     e.preventDefault();
 
@@ -613,6 +664,151 @@ export const MyPage: React.FC = () => {
         )}
       </div>
 
+      {/* Two-Factor Authentication Section */}
+      <div
+        style={{
+          backgroundColor: 'white',
+          border: '1px solid #e5e7eb',
+          borderRadius: '0.5rem',
+          padding: '1.5rem',
+          marginBottom: '2rem',
+        }}
+      >
+        <div
+          style={{
+            display: 'flex',
+            justifyContent: 'space-between',
+            alignItems: 'center',
+          }}
+        >
+          <div>
+            <h2
+              style={{
+                fontSize: '1.25rem',
+                fontWeight: '600',
+                marginBottom: '0.5rem',
+              }}
+            >
+              二要素認証
+            </h2>
+            <p style={{ color: '#6b7280', fontSize: '0.875rem' }}>
+              {twoFactorEnabled ? '有効' : '無効'}
+            </p>
+          </div>
+          {!twoFactorEnabled && !enrollment && (
+            <button
+              onClick={handleTwoFactorEnroll}
+              disabled={twoFactorLoading}
+              style={{
+                padding: '0.5rem 1rem',
+                backgroundColor: twoFactorLoading ? '#9ca3af' : '#2563eb',
+                color: 'white',
+                border: 'none',
+                borderRadius: '0.375rem',
+                fontSize: '0.875rem',
+                cursor: twoFactorLoading ? 'not-allowed' : 'pointer',
+              }}
+            >
+              設定する
+            </button>
+          )}
+        </div>
+
+        {twoFactorError && (
+          <div
+            style={{
+              backgroundColor: '#fef2f2',
+              border: '1px solid #fecaca',
+              color: '#b91c1c',
+              padding: '0.75rem',
+              borderRadius: '0.375rem',
+              marginTop: '1rem',
+              fontSize: '0.875rem',
+            }}
+          >
+            {twoFactorError}
+          </div>
+        )}
+
+        {enrollment && (
+          <form
+            onSubmit={handleTwoFactorConfirm}
+            style={{
+              marginTop: '1rem',
+              padding: '1rem',
+              backgroundColor: '#f9fafb',
+              borderRadius: '0.375rem',
+              fontSize: '0.875rem',
+            }}
+          >
+            <p style={{ marginBottom: '0.5rem' }}>
+              認証アプリに次のシークレットを登録し、表示されたコードを入力してください。
+            </p>
+            <p style={{ marginBottom: '0.5rem', wordBreak: 'break-all' }}>
+              <strong>シークレット:</strong> <code>{enrollment.secret}</code>
+            </p>
+            <p style={{ marginBottom: '1rem', wordBreak: 'break-all' }}>
+              <a href={enrollment.otpauth_uri} style={{ color: '#2563eb' }}>
+                {enrollment.otpauth_uri}
+              </a>
+            </p>
+            <div style={{ display: 'flex', gap: '0.5rem' }}>
+              <input
+                type="text"
+                autoComplete="one-time-code"
+                value={twoFactorCode}
+                onChange={(e) => setTwoFactorCode(e.target.value)}
+                required
+                placeholder="認証コード"
+                style={{
+                  flex: 1,
+                  padding: '0.5rem',
+                  border: '1px solid #d1d5db',
+                  borderRadius: '0.375rem',
+                  fontSize: '0.875rem',
+                }}
+              />
+              <button
+                type="submit"
+                disabled={twoFactorLoading}
+                style={{
+                  padding: '0.5rem 1rem',
+                  backgroundColor: twoFactorLoading ? '#9ca3af' : '#059669',
+                  color: 'white',
+                  border: 'none',
+                  borderRadius: '0.375rem',
+                  fontSize: '0.875rem',
+                  cursor: twoFactorLoading ? 'not-allowed' : 'pointer',
+                }}
+              >
+                有効にする
+              </button>
+            </div>
+          </form>
+        )}
+
+        {recoveryCodes.length > 0 && (
+          <div
+            style={{
+              marginTop: '1rem',
+              padding: '1rem',
+              backgroundColor: '#f9fafb',
+              borderRadius: '0.375rem',
+              fontSize: '0.875rem',
+            }}
+          >
+            <p style={{ marginBottom: '0.5rem' }}>
+              リカバリーコードを安全な場所に保管してください。この画面を離れると再表示できません。
+            </p>
+            <ul style={{ fontFamily: 'monospace', paddingLeft: '1.25rem' }}>
+              {recoveryCodes.map((recoveryCode) => (
+                <li key={recoveryCode}>{recoveryCode}</li>
+              ))}
+            </ul>
+          </div>
+        )}
+      </div>
+
       {/* Posts Section */}
       <div
         style={{
@@ -741,7 +937,7 @@ export const MyPage: React.FC = () => {
                     </p>
 
                     <div style={{ color: '#6b7280', fontSize: '0.875rem' }}>
-                      {post.replies?.length || 0} replies
+                      {post.replies_count || 0} replies
                     </div>
                   </div>
                 ))}
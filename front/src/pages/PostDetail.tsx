@@ -12,6 +12,9 @@ export const PostDetail: React.FC = () => {
   const [isAnonymous, setIsAnonymous] = useState(false);
   const [submittingReply, setSubmittingReply] = useState(false);
   const [replyError, setReplyError] = useState('');
+  const [replies, setReplies] = useState<Reply[]>([]);
+  const [repliesCursor, setRepliesCursor] = useState<string | undefined>();
+  const [loadingReplies, setLoadingReplies] = useState(false);
 
   const { id } = useParams<{ id: string }>();
   const { user } = useAuth();
@@ -29,11 +32,31 @@ export const PostDetail: React.FC = () => {
     }
   }, [id]);
 
+  // Replies are paged; passing a cursor appends the next page
+  const fetchReplies = useCallback(
+    async (cursor?: string) => {
+      try {
+        setLoadingReplies(true);
+        const response = await postApi.getReplies(Number(id), cursor);
+        setReplies((current) =>
+          cursor ? [...current, ...response.data] : response.data
+        );
+        setRepliesCursor(response.next_cursor || undefined);
+      } catch (err: any) {
+        console.error('Error fetching replies:', err);
+      } finally {
+        setLoadingReplies(false);
+      }
+    },
+    [id]
+  );
+
   useEffect(() => {
     if (id) {
       fetchPost();
+      fetchReplies();
     }
-  }, [id, fetchPost]);
+  }, [id, fetchPost, fetchReplies]);
 
   const handleReplySubmit = async (e: React.FormEvent) => {
     e.preventDefault();
@@ -61,8 +84,8 @@ export const PostDetail: React.FC = () => {
       setReplyContent('');
       setIsAnonymous(false);
 
-      // Refresh post to show new reply
-      await fetchPost();
+      // Refresh the reply count and list to show the new reply
+      await Promise.all([fetchPost(), fetchReplies()]);
     } catch (err: any) {
       setReplyError(err.response?.data?.message || 'Failed to create reply');
     } finally {
@@ -268,7 +291,7 @@ export const PostDetail: React.FC = () => {
             marginBottom: '1.5rem',
           }}
         >
-          Replies ({post.replies?.length || 0})
+          Replies ({post.replies_count || 0})
         </h2>
 
         {/* Reply Form */}
@@ -409,11 +432,11 @@ export const PostDetail: React.FC = () => {
         )}
 
         {/* Replies List */}
-        {post.replies && post.replies.length > 0 ? (
+        {replies.length > 0 ? (
           <div
             style={{ display: 'flex', flexDirection: 'column', gap: '1rem' }}
           >
-            {post.replies.map((reply: Reply) => (
+            {replies.map((reply: Reply) => (
               <div
                 key={reply.id}
                 style={{
@@ -452,10 +475,33 @@ export const PostDetail: React.FC = () => {
                     wordBreak: 'break-word',
                   }}
                 >
-                  {reply.content}
+                  {reply.is_deleted ? (
+                    <span style={{ fontStyle: 'italic', color: '#9ca3af' }}>
+                      This reply was deleted.
+                    </span>
+                  ) : (
+                    reply.content
+                  )}
                 </div>
               </div>
             ))}
+            {repliesCursor && (
+              <button
+                onClick={() => fetchReplies(repliesCursor)}
+                disabled={loadingReplies}
+                style={{
+                  padding: '0.5rem 1rem',
+                  backgroundColor: 'white',
+                  color: '#2563eb',
+                  border: '1px solid #d1d5db',
+                  borderRadius: '0.375rem',
+                  fontSize: '0.875rem',
+                  cursor: loadingReplies ? 'not-allowed' : 'pointer',
+                }}
+              >
+                {loadingReplies ? 'Loading...' : 'Load more replies'}
+              </button>
+            )}
           </div>
         ) : (
           <div
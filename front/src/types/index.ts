@@ -17,7 +17,7 @@ export interface Post {
   thumbnail_url?: string;
   author: User;
   status: 'pending' | 'approved' | 'rejected';
-  replies?: Reply[];
+  replies_count?: number;
   created_at: string;
   updated_at: string;
 }
@@ -28,6 +28,8 @@ export interface Reply {
   post_id: number;
   author?: User;
   is_anonymous: boolean;
+  is_deleted?: boolean;
+  replies_count?: number;
   created_at: string;
 }
 
@@ -36,6 +38,7 @@ export interface PaginatedResponse<T> {
   total: number;
   page: number;
   limit: number;
+  next_cursor?: string;
 }
 
 export interface SubscriptionStatus {
@@ -1,4 +1,5 @@
 import { axiosInstance } from './api-mutator';
+import { PaginatedResponse, Reply } from '../types';
 
 export interface LoginResponse {
   user: {
@@ -15,8 +16,23 @@ export interface LoginResponse {
   access_token: string;
 }
 
+// Returned by login instead of tokens when the account has two-factor
+// authentication enabled; exchange mfa_token via authApi.verifyTwoFactor
+export interface TwoFactorChallenge {
+  mfa_required: true;
+  mfa_token: string;
+}
+
+export const isTwoFactorChallenge = (
+  response: LoginResponse | TwoFactorChallenge
+): response is TwoFactorChallenge =>
+  (response as TwoFactorChallenge).mfa_required === true;
+
 export const authApi = {
-  login: async (email: string, password: string): Promise<LoginResponse> => {
+  login: async (
+    email: string,
+    password: string
+  ): Promise<LoginResponse | TwoFactorChallenge> => {
     const response = await axiosInstance.post('/auth/login', {
       email,
       password,
@@ -37,7 +53,10 @@ export const authApi = {
     return response.data;
   },
 
-  adminLogin: async (email: string, password: string): Promise<LoginResponse> => {
+  adminLogin: async (
+    email: string,
+    password: string
+  ): Promise<LoginResponse | TwoFactorChallenge> => {
     const response = await axiosInstance.post('/admin/login', {
       email,
       password,
@@ -45,6 +64,17 @@ export const authApi = {
     return response.data;
   },
 
+  verifyTwoFactor: async (
+    mfa_token: string,
+    code: string
+  ): Promise<LoginResponse> => {
+    const response = await axiosInstance.post('/auth/2fa/verify', {
+      mfa_token,
+      code,
+    });
+    return response.data;
+  },
+
   logout: async (): Promise<void> => {
     await axiosInstance.post('/auth/logout');
   },
@@ -76,6 +106,26 @@ export const authApi = {
   },
 };
 
+export const twoFactorApi = {
+  getStatus: async (): Promise<{
+    enabled: boolean;
+    recovery_codes_remaining: number;
+  }> => {
+    const response = await axiosInstance.get('/user/2fa');
+    return response.data;
+  },
+
+  enroll: async (): Promise<{ secret: string; otpauth_uri: string }> => {
+    const response = await axiosInstance.post('/user/2fa/enroll');
+    return response.data;
+  },
+
+  confirm: async (code: string): Promise<{ recovery_codes: string[] }> => {
+    const response = await axiosInstance.post('/user/2fa/confirm', { code });
+    return response.data;
+  },
+};
+
 export const userApi = {
   getProfile: async (): Promise<any> => {
     const response = await axiosInstance.get('/user/profile');
@@ -141,6 +191,20 @@ export const postApi = {
     await axiosInstance.delete(`/posts/${id}`);
   },
 
+  getReplies: async (
+    postId: number,
+    cursor?: string
+  ): Promise<PaginatedResponse<Reply>> => {
+    const params = new URLSearchParams({ limit: '20' });
+    if (cursor) {
+      params.set('cursor', cursor);
+    }
+    const response = await axiosInstance.get(
+      `/posts/${postId}/replies?${params.toString()}`
+    );
+    return response.data;
+  },
+
   createReply: async (
     postId: number,
     content: string,